@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/agent"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// noAgentEnv bypasses the agent for one invocation when set to 1, true or yes
+const noAgentEnv = "SMART_KEYVAULT_NO_AGENT"
+
+var (
+	agentIdleTimeout time.Duration // --idle-timeout for agent run/start
+
+	agentOnce   sync.Once
+	agentCached *agent.Client
+)
+
+// agentSocketPath returns the configured or default agent socket
+func agentSocketPath() (string, error) {
+	if appConfig != nil && appConfig.Agent.Socket != "" {
+		return appConfig.Agent.Socket, nil
+	}
+	return agent.DefaultSocketPath()
+}
+
+// agentClient returns a client for the running agent, or nil when no agent
+// is running or it is disabled. The answer is cached for the invocation.
+func agentClient() *agent.Client {
+	agentOnce.Do(func() {
+		if appConfig == nil || !appConfig.Agent.Enabled {
+			return
+		}
+		switch strings.ToLower(os.Getenv(noAgentEnv)) {
+		case "1", "true", "yes":
+			return
+		}
+
+		path, err := agentSocketPath()
+		if err != nil {
+			return
+		}
+		if client, err := agent.Connect(path); err == nil {
+			agentCached = client
+		}
+	})
+	return agentCached
+}
+
+// localProvider creates a provider in this process
+// It returns the provider and the resolved instance name.
+func localProvider(name, instance string) (provider.Provider, string, error) {
+	cfg, err := getProviderConfig(name, instance)
+	if err != nil {
+		return nil, "", err
+	}
+
+	p, err := provider.GetProvider(name, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, cfg.Instance, nil
+}
+
+// agentCmd returns the agent command group
+func agentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run a background agent that keeps providers and credentials warm",
+		Long: `The agent is a long-running process listening on a user-only Unix socket.
+It keeps provider clients, credentials and vault/secret listings warm so each
+CLI invocation skips authentication. Commands use a running agent
+automatically; set SMART_KEYVAULT_NO_AGENT=1 to bypass it.
+
+The agent reads the config file when it starts; restart it after changes.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	cmd.AddCommand(agentRunCmd())
+	cmd.AddCommand(agentStartCmd())
+	cmd.AddCommand(agentSimpleCmd("stop", "Stop the running agent", agent.OpStop, "Agent stopped"))
+	cmd.AddCommand(agentSimpleCmd("lock", "Drop cached credentials and refuse requests until unlocked", agent.OpLock, "Agent locked"))
+	cmd.AddCommand(agentSimpleCmd("unlock", "Accept requests again after a lock", agent.OpUnlock, "Agent unlocked"))
+	cmd.AddCommand(agentStatusCmd())
+	return cmd
+}
+
+// agentRunCmd returns the agent run command (foreground)
+func agentRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the agent in the foreground",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			path, err := agentSocketPath()
+			if err != nil {
+				return err
+			}
+
+			idle := appConfig.Agent.IdleTimeout
+			if cmd.Flags().Changed("idle-timeout") {
+				idle = agentIdleTimeout
+			}
+
+			server := agent.NewServer(agent.Options{
+				SocketPath:  path,
+				IdleTimeout: idle,
+				MetadataTTL: appConfig.Agent.MetadataTTL,
+				Cache:       appConfig.Agent.Cache,
+			}, localProvider)
+
+			// Remove the socket when interrupted
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-signals
+				server.Close()
+			}()
+
+			fmt.Fprintf(os.Stderr, "Agent listening on %s\n", path)
+			return server.Serve()
+		},
+	}
+
+	cmd.Flags().DurationVar(&agentIdleTimeout, "idle-timeout", 0, "Stop after this long without requests (default from config, 0 never stops)")
+	return cmd
+}
+
+// agentStartCmd returns the agent start command (background)
+func agentStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the agent in the background",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			path, err := agentSocketPath()
+			if err != nil {
+				return err
+			}
+			if _, err := agent.Connect(path); err == nil {
+				fmt.Fprintf(os.Stderr, "Agent already running on %s\n", path)
+				return nil
+			}
+
+			self, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate executable: %w", err)
+			}
+
+			runArgs := []string{"agent", "run"}
+			if configPath != "" {
+				runArgs = append(runArgs, "--config", configPath)
+			}
+			if cmd.Flags().Changed("idle-timeout") {
+				runArgs = append(runArgs, "--idle-timeout", agentIdleTimeout.String())
+			}
+
+			child := exec.Command(self, runArgs...)
+			child.SysProcAttr = detachAttr()
+			if err := child.Start(); err != nil {
+				return fmt.Errorf("failed to start agent: %w", err)
+			}
+			child.Process.Release()
+
+			// Wait until the socket answers
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				if _, err := agent.Connect(path); err == nil {
+					fmt.Fprintf(os.Stderr, "Agent started on %s\n", path)
+					return nil
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+			return fmt.Errorf("agent did not start listening on %s", path)
+		},
+	}
+
+	cmd.Flags().DurationVar(&agentIdleTimeout, "idle-timeout", 0, "Stop after this long without requests (default from config, 0 never stops)")
+	return cmd
+}
+
+// agentSimpleCmd returns a command that sends one operation to the agent
+func agentSimpleCmd(use, short, op, done string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := connectAgent()
+			if err != nil {
+				return err
+			}
+
+			if _, err := client.Call(context.Background(), &agent.Request{Op: op}); err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, done)
+			return nil
+		},
+	}
+}
+
+// agentStatusCmd returns the agent status command
+func agentStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the agent is running and what it holds",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := connectAgent()
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Call(context.Background(), &agent.Request{Op: agent.OpStatus})
+			if err != nil {
+				return err
+			}
+
+			switch formatType {
+			case "json":
+				data, err := json.MarshalIndent(resp.Status, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			case "plain":
+				st := resp.Status
+				fmt.Printf("pid: %d\nlocked: %t\nuptime: %s\nidle: %s\n", st.PID, st.Locked, st.Uptime, st.IdleFor)
+				for _, inst := range st.Instances {
+					fmt.Printf("instance: %s\n", inst)
+				}
+				if st.Cache != nil && st.Cache.Enabled {
+					fmt.Printf("cache: %d/%d values (locked in memory: %t)\n", st.Cache.Entries, st.Cache.MaxEntries, st.Cache.Locked)
+				} else {
+					fmt.Println("cache: disabled")
+				}
+			default:
+				return newUsageError("unsupported format: %s (use plain or json)", formatType)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	return cmd
+}
+
+// connectAgent loads config and connects to the running agent
+func connectAgent() (*agent.Client, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	path, err := agentSocketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := agent.Connect(path)
+	if err != nil {
+		return nil, provider.NewError(provider.ErrUnavailable, fmt.Errorf("agent is not running on %s", path))
+	}
+	return client, nil
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// queryAll is the --all flag shared by the listing commands
+var queryAll bool
+
+// opener creates a guarded provider for an instance (see openProvider)
+type opener func(name, instance string) (provider.Provider, *provider.Config, error)
+
+// instanceResult holds the outcome of querying one provider instance
+type instanceResult[T any] struct {
+	instance config.InstanceRef
+	items    []T
+	err      error
+}
+
+// queryInstances runs fn concurrently against every enabled provider instance
+// (or those selected by --provider and --instance). Results come back in
+// config order regardless of completion order. Failing instances are reported
+// on stderr and skipped; an error is returned only when no instance succeeded.
+func queryInstances[T any](ctx context.Context, fn func(ctx context.Context, inst config.InstanceRef, p provider.Provider) ([]T, error)) ([]T, error) {
+	return queryInstanceList(ctx, targetInstances(), openProvider, fn)
+}
+
+// queryInstanceList is queryInstances for an explicit list of instances
+// opened with open
+func queryInstanceList[T any](ctx context.Context, instances []config.InstanceRef, open opener, fn func(ctx context.Context, inst config.InstanceRef, p provider.Provider) ([]T, error)) ([]T, error) {
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no enabled provider instances configured")
+	}
+
+	results := make([]instanceResult[T], len(instances))
+
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		go func(i int, inst config.InstanceRef) {
+			defer wg.Done()
+
+			results[i].instance = inst
+
+			p, _, err := open(inst.Provider, inst.Name)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+
+			results[i].items, results[i].err = fn(ctx, inst, p)
+		}(i, inst)
+	}
+	wg.Wait()
+
+	var merged []T
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			fmt.Fprintf(os.Stderr, "Warning: %s/%s: %v\n", r.instance.Provider, r.instance.Name, r.err)
+			continue
+		}
+		merged = append(merged, r.items...)
+	}
+
+	// Keep the underlying errors so the exit code reflects their kind
+	if len(errs) == len(results) {
+		return nil, fmt.Errorf("all %d provider instances failed: %w", len(errs), errors.Join(errs...))
+	}
+
+	return merged, nil
+}
+
+// listAllVaults lists vaults from every enabled provider instance
+func listAllVaults(ctx context.Context) ([]*models.Vault, error) {
+	return listVaultsOf(ctx, targetInstances(), openProvider)
+}
+
+// listVaultsOf lists vaults from the given instances
+func listVaultsOf(ctx context.Context, instances []config.InstanceRef, open opener) ([]*models.Vault, error) {
+	return queryInstanceList(ctx, instances, open, func(ctx context.Context, inst config.InstanceRef, p provider.Provider) ([]*models.Vault, error) {
+		vaults, err := p.ListVaults(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range vaults {
+			v.Provider = inst.Provider
+			v.Instance = inst.Name
+		}
+		return vaults, nil
+	})
+}
+
+// listAllSecrets lists secrets from every enabled provider instance
+// If vault is set only vaults with that name are listed, otherwise every vault
+// of every instance is listed.
+func listAllSecrets(ctx context.Context, vault string) ([]*models.Secret, error) {
+	return listSecretsOf(ctx, targetInstances(), openProvider, vault)
+}
+
+// listSecretsOf lists secrets from the given instances, in every vault or
+// only in vaults named vault
+func listSecretsOf(ctx context.Context, instances []config.InstanceRef, open opener, vault string) ([]*models.Secret, error) {
+	return queryInstanceList(ctx, instances, open, func(ctx context.Context, inst config.InstanceRef, p provider.Provider) ([]*models.Secret, error) {
+		return listInstanceSecrets(ctx, inst, p, vault)
+	})
+}
+
+// listInstanceSecrets lists the secrets of one instance, in every vault or
+// only in vaults named vault. Vaults that fail to list are reported on stderr
+// and skipped.
+func listInstanceSecrets(ctx context.Context, inst config.InstanceRef, p provider.Provider, vault string) ([]*models.Secret, error) {
+	var vaults []*models.Vault
+	if vault != "" {
+		vaults = []*models.Vault{{Name: vault}}
+	} else {
+		allVaults, err := p.ListVaults(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vaults = allVaults
+	}
+
+	var secrets []*models.Secret
+	for _, v := range vaults {
+		vaultSecrets, err := p.ListSecrets(ctx, v.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s/%s: failed to list secrets in vault %s: %v\n", inst.Provider, inst.Name, v.Name, err)
+			continue
+		}
+
+		for _, s := range vaultSecrets {
+			s.Provider = inst.Provider
+			s.Instance = inst.Name
+		}
+		secrets = append(secrets, filterSecrets(vaultSecrets)...)
+	}
+	return secrets, nil
+}
+
+// targetInstances returns the enabled instances, restricted to --provider and
+// --instance when they are set
+func targetInstances() []config.InstanceRef {
+	return selectInstances(providerName, instanceName)
+}
+
+// selectInstances returns the enabled instances of a provider and instance
+// ("" matches any)
+func selectInstances(providerFilter, instanceFilter string) []config.InstanceRef {
+	var instances []config.InstanceRef
+	for _, inst := range appConfig.EnabledInstances() {
+		if providerFilter != "" && inst.Provider != providerFilter {
+			continue
+		}
+		if instanceFilter != "" && inst.Name != instanceFilter {
+			continue
+		}
+		instances = append(instances, inst)
+	}
+	return instances
+}
+
+// streamInstances runs fn concurrently against every enabled provider instance
+// and passes items to emit as soon as any instance produces them. emit is only
+// called from the calling goroutine, so it can write to stdout without locking.
+// Failing instances are reported on stderr; an error is returned only when no
+// instance succeeded or emit fails.
+func streamInstances[T any](ctx context.Context, emit func(T) error, fn func(ctx context.Context, inst config.InstanceRef, p provider.Provider, send func(T) bool) error) error {
+	instances := targetInstances()
+	if len(instances) == 0 {
+		return fmt.Errorf("no enabled provider instances configured")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make(chan T)
+	errs := make([]error, len(instances))
+
+	// send hands an item to the emitting goroutine; false means stop producing
+	send := func(item T) bool {
+		select {
+		case items <- item:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		go func(i int, inst config.InstanceRef) {
+			defer wg.Done()
+
+			p, _, err := openProvider(inst.Provider, inst.Name)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			errs[i] = fn(ctx, inst, p, send)
+		}(i, inst)
+	}
+
+	go func() {
+		wg.Wait()
+		close(items)
+	}()
+
+	var emitErr error
+	for item := range items {
+		if emitErr != nil {
+			continue // drain so producers can exit
+		}
+		if emitErr = emit(item); emitErr != nil {
+			cancel()
+		}
+	}
+	if emitErr != nil {
+		return emitErr
+	}
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+			fmt.Fprintf(os.Stderr, "Warning: %s/%s: %v\n", instances[i].Provider, instances[i].Name, err)
+		}
+	}
+
+	if len(failed) == len(instances) {
+		return fmt.Errorf("all %d provider instances failed: %w", len(failed), errors.Join(failed...))
+	}
+
+	return nil
+}
+
+// streamAllVaults emits vaults from every enabled provider instance as they arrive
+func streamAllVaults(ctx context.Context, emit func(*models.Vault) error) error {
+	return streamInstances(ctx, emit, func(ctx context.Context, inst config.InstanceRef, p provider.Provider, send func(*models.Vault) bool) error {
+		for v, err := range provider.StreamVaults(ctx, p) {
+			if err != nil {
+				return err
+			}
+
+			v.Provider = inst.Provider
+			v.Instance = inst.Name
+			if !send(v) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// streamAllSecrets emits secrets from every enabled provider instance as they arrive
+// If vault is set only vaults with that name are listed.
+func streamAllSecrets(ctx context.Context, vault string, emit func(*models.Secret) error) error {
+	return streamInstances(ctx, emit, func(ctx context.Context, inst config.InstanceRef, p provider.Provider, send func(*models.Secret) bool) error {
+		var vaults []*models.Vault
+		if vault != "" {
+			vaults = []*models.Vault{{Name: vault}}
+		} else {
+			allVaults, err := p.ListVaults(ctx)
+			if err != nil {
+				return err
+			}
+			vaults = allVaults
+		}
+
+		for _, v := range vaults {
+			for s, err := range provider.StreamSecrets(ctx, p, v.Name) {
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s/%s: failed to list secrets in vault %s: %v\n", inst.Provider, inst.Name, v.Name, err)
+					break
+				}
+				if !showSecret(s) {
+					continue
+				}
+
+				s.Provider = inst.Provider
+				s.Instance = inst.Name
+				if !send(s) {
+					return nil
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// editableConfigPath returns the config file that alias commands modify
+func editableConfigPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	return config.DefaultConfigPath()
+}
+
+// aliasCmd returns the alias command
+func aliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage short names for frequently used secrets",
+	}
+
+	cmd.AddCommand(aliasAddCmd())
+	cmd.AddCommand(aliasRemoveCmd())
+	cmd.AddCommand(aliasListCmd())
+	return cmd
+}
+
+// aliasAddCmd returns the alias add command
+func aliasAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <alias>",
+		Short: "Add or replace an alias in the config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alias := config.Alias{
+				Provider: providerName,
+				Instance: instanceName,
+				Vault:    vaultName,
+				Secret:   secretName,
+				Field:    fieldName,
+			}
+
+			if secretRef != "" {
+				ref, err := models.ParseSecretRef(secretRef)
+				if err != nil {
+					return err
+				}
+				alias.Provider, alias.Instance, alias.Vault, alias.Secret = ref.Provider, ref.Instance, ref.Vault, ref.Secret
+			}
+
+			if alias.Provider != "" && !provider.IsRegistered(alias.Provider) {
+				return fmt.Errorf("provider not found: %s", alias.Provider)
+			}
+
+			path, err := editableConfigPath()
+			if err != nil {
+				return err
+			}
+
+			if err := config.SetAlias(path, args[0], alias); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Alias '%s' saved to %s\n", strings.ToLower(args[0]), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
+	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
+	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name")
+	cmd.Flags().StringVarP(&secretName, "name", "n", "", "Secret name")
+	cmd.Flags().StringVarP(&secretRef, "ref", "r", "", "Secret reference as provider/instance/vault/secret (replaces --provider, --instance, --vault and --name)")
+	cmd.Flags().StringVar(&fieldName, "field", "", "Field to return from a multi-field secret (optional)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// aliasRemoveCmd returns the alias remove command
+func aliasRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <alias>",
+		Aliases: []string{"rm"},
+		Short:   "Remove an alias from the config file",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := editableConfigPath()
+			if err != nil {
+				return err
+			}
+
+			if err := config.RemoveAlias(path, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Alias '%s' removed from %s\n", strings.ToLower(args[0]), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// aliasListCmd returns the alias list command
+func aliasListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured aliases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Load config
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			switch formatType {
+			case "json":
+				data, err := json.MarshalIndent(appConfig.Aliases, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			case "plain":
+				names := make([]string, 0, len(appConfig.Aliases))
+				for name := range appConfig.Aliases {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				// Format: alias<TAB>provider/instance/vault/secret[#field]
+				for _, name := range names {
+					alias := appConfig.Aliases[name]
+					target := models.SecretRef{
+						Provider: alias.Provider,
+						Instance: alias.Instance,
+						Vault:    alias.Vault,
+						Secret:   alias.Secret,
+					}.String()
+					if alias.Field != "" {
+						target += "#" + alias.Field
+					}
+					fmt.Printf("%s\t%s\n", name, target)
+				}
+			default:
+				return fmt.Errorf("unsupported format: %s", formatType)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/audit"
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+var (
+	expiringWithinDays int  // --expiring-within for audit expiry
+	maxAgeDays         int  // --max-age for audit expiry
+	includeOK          bool // --include-ok for audit reports
+)
+
+// auditCmd returns the audit command group
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report on secret expiry and access",
+	}
+
+	cmd.AddCommand(auditExpiryCmd())
+	cmd.AddCommand(auditLogCmd())
+	return cmd
+}
+
+// auditExpiryCmd returns the audit expiry command
+func auditExpiryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expiry",
+		Short: "Report expired, expiring and unrotated secrets",
+		Long: `Walk the vaults of every enabled provider instance (or those selected with
+--provider and --instance) and report secrets that are expired, expire within
+--expiring-within days, or have not been updated for more than --max-age days.
+
+Only metadata is read: Azure expiry/update attributes and KV v2 version
+timestamps (delete_version_after counts as expiry). Secret values are never
+fetched.
+
+Exits with code 8 when any secret breaches a threshold.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			if expiringWithinDays < 0 || maxAgeDays < 0 {
+				return newUsageError("--expiring-within and --max-age must not be negative")
+			}
+			switch formatType {
+			case audit.FormatTable, audit.FormatJSON, audit.FormatJUnit:
+			default:
+				return newUsageError("unsupported format: %s (use table, json or junit)", formatType)
+			}
+
+			thresholds := audit.Thresholds{
+				ExpiringWithin: days(expiringWithinDays),
+				MaxAge:         days(maxAgeDays),
+			}
+
+			secrets, err := queryInstances(context.Background(), listSecretTimestamps)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			findings := []audit.Finding{}
+			breached := 0
+			for _, s := range secrets {
+				f := audit.CheckExpiry(s, now, thresholds)
+				if f.Breached() {
+					breached++
+				}
+				// JUnit lists every secret so CI shows passing checks too
+				if f.Breached() || includeOK || formatType == audit.FormatJUnit {
+					findings = append(findings, f)
+				}
+			}
+			audit.SortFindings(findings)
+
+			result, err := audit.FormatFindings("expiry", findings, formatType)
+			if err != nil {
+				return err
+			}
+			if len(findings) == 0 && formatType == audit.FormatTable {
+				fmt.Fprintf(os.Stderr, "Checked %d secrets: none breach the thresholds\n", len(secrets))
+			} else {
+				fmt.Println(result)
+			}
+
+			if breached > 0 {
+				return newFindingsError("%d secret(s) breach the expiry thresholds", breached)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Only audit this provider (default: all enabled providers)")
+	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Only audit this instance (default: all instances)")
+	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Only audit vaults with this name")
+	cmd.Flags().IntVar(&expiringWithinDays, "expiring-within", 30, "Report secrets expiring within this many days (0 reports only expired)")
+	cmd.Flags().IntVar(&maxAgeDays, "max-age", 0, "Report secrets not updated for more than this many days (0 disables)")
+	cmd.Flags().BoolVar(&includeOK, "include-ok", false, "Also list secrets that pass every check")
+	cmd.Flags().StringVarP(&formatType, "format", "f", audit.FormatTable, "Output format (table, json, junit)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// listSecretTimestamps lists an instance's secrets with their timestamps
+// Providers whose listings omit timestamps are asked for each secret's metadata.
+func listSecretTimestamps(ctx context.Context, inst config.InstanceRef, p provider.Provider) ([]*models.Secret, error) {
+	secrets, err := listInstanceSecrets(ctx, inst, p, vaultName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := p.(provider.MetadataReader)
+	if !ok {
+		return secrets, nil
+	}
+
+	for i, s := range secrets {
+		if s.UpdatedOn != nil || s.ExpiresOn != nil {
+			continue
+		}
+
+		meta, err := reader.GetSecretMetadata(ctx, s.VaultName, s.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s/%s: failed to read metadata of %s/%s: %v\n", inst.Provider, inst.Name, s.VaultName, s.Name, err)
+			continue
+		}
+
+		meta.Provider = inst.Provider
+		meta.Instance = inst.Name
+		secrets[i] = meta
+	}
+	return secrets, nil
+}
+
+// days converts a day count to a duration
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
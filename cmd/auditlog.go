@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/auditlog"
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/output"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+var (
+	commandName    string        // Name of the running subcommand, recorded in the audit log
+	verifyLog      bool          // --verify for audit log
+	logSince       time.Duration // --since for audit log
+	auditLogLimit  int           // --limit for audit log
+	auditLogFilter string        // --ref for audit log
+
+	// The audit log and its sinks are opened once per process, so walks and
+	// the local API do not open a syslog connection per secret
+	auditLogOnce sync.Once
+	auditLog     *auditlog.Log
+	auditLogErr  error
+)
+
+// auditLogPath returns the location of the local audit log
+func auditLogPath() (string, error) {
+	if appConfig != nil && appConfig.AuditLog.Path != "" {
+		return appConfig.AuditLog.Path, nil
+	}
+
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, auditlog.DefaultFileName), nil
+}
+
+// openAuditLog opens the local audit log with the configured sinks
+func openAuditLog() (*auditlog.Log, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// An unavailable sink must not stop the local log from being written
+	var sinks []auditlog.Sink
+	if appConfig.AuditLog.Syslog {
+		sink, err := auditlog.NewSyslogSink()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	if appConfig.AuditLog.JSONLines != "" {
+		sinks = append(sinks, auditlog.NewJSONLinesSink(appConfig.AuditLog.JSONLines))
+	}
+
+	return auditlog.Open(path, sinks...), nil
+}
+
+// recordAccess appends a secret read to the audit log
+// readErr is the outcome of the read. Logging failures are warnings unless
+// audit_log.required is set, in which case they are returned so the caller
+// withholds the value.
+func recordAccess(command string, ref models.SecretRef, field string, readErr error) error {
+	if appConfig == nil || !appConfig.AuditLog.Enabled {
+		return nil
+	}
+
+	entry := auditlog.NewEntry(command, ref)
+	entry.Field = field
+	if readErr != nil {
+		entry.Outcome = auditlog.OutcomeError
+		entry.Error = readErr.Error()
+	}
+
+	auditLogOnce.Do(func() { auditLog, auditLogErr = openAuditLog() })
+	err := auditLogErr
+	if err == nil {
+		err = auditLog.Append(entry)
+	}
+	if err != nil {
+		if appConfig.AuditLog.Required {
+			return fmt.Errorf("failed to write audit log (audit_log.required is set): %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: failed to write audit log: %v\n", err)
+	}
+	return nil
+}
+
+// closeAuditLog releases the sinks of the audit log, if it was opened
+func closeAuditLog() {
+	if auditLog != nil {
+		auditLog.Close()
+	}
+}
+
+// auditLogCmd returns the audit log command
+func auditLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show or verify the local log of secret reads",
+		Long: `Show the local audit log of secret reads (get-secret, copy, walk-secrets and
+lint). Entries record who read which secret reference from which host and
+when, and whether the read succeeded; values are never logged.
+
+Entries are hash-chained: --verify recomputes the chain and exits with code 8
+if any entry was edited, inserted or removed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			if formatType != "table" && formatType != "json" {
+				return newUsageError("unsupported format: %s (use table or json)", formatType)
+			}
+
+			path, err := auditLogPath()
+			if err != nil {
+				return err
+			}
+
+			entries, err := auditlog.Read(path)
+			if err != nil {
+				return err
+			}
+
+			if verifyLog {
+				if err := auditlog.Verify(entries); err != nil {
+					var chainErr *auditlog.ChainError
+					if errors.As(err, &chainErr) {
+						return newFindingsError("%v", err)
+					}
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Verified %d entries in %s: chain intact\n", len(entries), path)
+				return nil
+			}
+
+			var ref *models.SecretRef
+			if auditLogFilter != "" {
+				parsed, err := models.ParseSecretRef(auditLogFilter)
+				if err != nil {
+					return newUsageError("%v", err)
+				}
+				ref = &parsed
+			}
+
+			entries = filterEntries(entries, ref)
+
+			if formatType == "json" {
+				if entries == nil {
+					entries = []*auditlog.Entry{}
+				}
+				data, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			if len(entries) == 0 {
+				fmt.Fprintln(os.Stderr, "No matching audit log entries")
+				return nil
+			}
+
+			rows := [][]string{{"TIME", "USER", "HOST", "COMMAND", "SECRET", "OUTCOME"}}
+			for _, e := range entries {
+				ref := e.SecretRef.String()
+				if e.Field != "" {
+					ref += "#" + e.Field
+				}
+				rows = append(rows, []string{e.Time.Local().Format(time.DateTime), e.User, e.Host, e.Command, ref, e.Outcome})
+			}
+			fmt.Println(output.WriteTable(rows))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&verifyLog, "verify", false, "Verify the hash chain instead of listing entries")
+	cmd.Flags().DurationVar(&logSince, "since", 0, "Only show entries newer than this (e.g. 24h)")
+	cmd.Flags().StringVarP(&auditLogFilter, "ref", "r", "", "Only show entries for this secret reference (provider/instance/vault/secret)")
+	cmd.Flags().IntVarP(&auditLogLimit, "limit", "n", 0, "Show only the newest N entries (0 shows all)")
+	cmd.Flags().StringVarP(&formatType, "format", "f", "table", "Output format (table, json)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// filterEntries applies the --since, --ref and --limit flags
+func filterEntries(entries []*auditlog.Entry, ref *models.SecretRef) []*auditlog.Entry {
+	var filtered []*auditlog.Entry
+	cutoff := time.Now().Add(-logSince)
+	for _, e := range entries {
+		if logSince > 0 && e.Time.Before(cutoff) {
+			continue
+		}
+		if ref != nil && e.SecretRef != *ref {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	if auditLogLimit > 0 && len(filtered) > auditLogLimit {
+		filtered = filtered[len(filtered)-auditLogLimit:]
+	}
+	return filtered
+}
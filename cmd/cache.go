@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/agent"
+)
+
+// cacheCmd returns the cache command group
+func cacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the agent's in-memory secret value cache",
+		Long: `Secret values are cached only inside a running agent, and only when
+agent.cache.enabled is set. Values are kept in locked memory where the OS
+allows it and are zeroised when they expire, are evicted or are flushed.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	cmd.AddCommand(cacheFlushCmd())
+	return cmd
+}
+
+// cacheFlushCmd returns the cache flush command
+func cacheFlushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "flush",
+		Short: "Drop every cached secret value",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			path, err := agentSocketPath()
+			if err != nil {
+				return err
+			}
+
+			// Values only live in the agent, so without one there is nothing to flush
+			client, err := agent.Connect(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Agent is not running; nothing is cached")
+				return nil
+			}
+
+			resp, err := client.Call(context.Background(), &agent.Request{Op: agent.OpCacheFlush})
+			if err != nil {
+				return fmt.Errorf("failed to flush cache: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Flushed %d cached values\n", resp.Flushed)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files: go test ./cmd -update
+var update = flag.Bool("update", false, "update golden files")
+
+// cliEnv makes the test binary run the CLI instead of the tests
+const cliEnv = "SMART_KEYVAULT_TEST_CLI"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(cliEnv) == "1" {
+		main()
+		os.Exit(ExitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs the CLI against the memory fixture in testdata and returns its
+// stdout, stderr and exit code
+func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	home := t.TempDir()
+	cmd := exec.Command(os.Args[0], append(args, "--config", "cmd/testdata/config.yaml")...)
+	cmd.Dir = ".." // The fixture path in the config is relative to the repository root
+	cmd.Env = append(os.Environ(),
+		cliEnv+"=1",
+		"HOME="+home,
+		"USERPROFILE="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"SMART_KEYVAULT_NO_AGENT=1",
+		"SMART_KEYVAULT_CONFIRM=",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run CLI: %v", err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"list-vaults.json", []string{"list-vaults", "-p", "memory", "-f", "json"}},
+		{"list-vaults.plain", []string{"list-vaults", "-p", "memory"}},
+		{"list-secrets.json", []string{"list-secrets", "-p", "memory", "-v", "app", "-f", "json"}},
+		{"list-secrets.table", []string{"list-secrets", "-p", "memory", "-v", "app", "-f", "table"}},
+		{"get-secret.json", []string{"get-secret", "-p", "memory", "-v", "app", "-n", "api-key", "-f", "json"}},
+		{"get-secret-version.plain", []string{"get-secret", "-p", "memory", "-v", "app", "-n", "api-key", "--version", "1"}},
+		{"get-secret-field.plain", []string{"get-secret", "-p", "memory", "-v", "app", "-n", "database", "--field", "username"}},
+		{"get-secret-field.json", []string{"get-secret", "-p", "memory", "-v", "app", "-n", "database", "--field", "username", "-f", "json"}},
+		{"get-secret-ref.plain", []string{"get-secret", "--ref", "memory/golden/app/api-key"}},
+		{"walk-secrets.json", []string{"walk-secrets", "-p", "memory", "-v", "app", "-f", "json"}},
+		{"walk-secrets.yaml", []string{"walk-secrets", "-p", "memory", "-f", "yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, tt.args...)
+			if code != ExitOK {
+				t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+			}
+
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(stdout), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if stdout != string(want) {
+				t.Errorf("output differs from %s\ngot:\n%s\nwant:\n%s", path, stdout, want)
+			}
+		})
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		wantErr string
+	}{
+		{
+			name:    "missing secret",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "missing"},
+			code:    ExitNotFound,
+			wantErr: "not found",
+		},
+		{
+			name:    "missing version",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "api-key", "--version", "9"},
+			code:    ExitNotFound,
+			wantErr: "has no version '9'",
+		},
+		{
+			name:    "missing vault",
+			args:    []string{"list-secrets", "-p", "memory", "-v", "missing"},
+			code:    ExitNotFound,
+			wantErr: "not found",
+		},
+		{
+			name:    "missing field",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "database", "--field", "host"},
+			code:    ExitNotFound,
+			wantErr: "has no field 'host'",
+		},
+		{
+			name:    "permission denied vault",
+			args:    []string{"list-secrets", "-p", "memory", "-v", "forbidden"},
+			code:    ExitPermissionDenied,
+			wantErr: "injected permission_denied error",
+		},
+		{
+			name:    "expired credentials",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "expired"},
+			code:    ExitAuthExpired,
+			wantErr: "injected auth_expired error",
+		},
+		{
+			name:    "sealed",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "sealed"},
+			code:    ExitSealed,
+			wantErr: "injected sealed error",
+		},
+		{
+			name:    "unavailable",
+			args:    []string{"get-secret", "-p", "memory", "-v", "app", "-n", "flaky"},
+			code:    ExitUnavailable,
+			wantErr: "injected unavailable error",
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"list-vaults", "--colour"},
+			code:    ExitUsage,
+			wantErr: "unknown flag: --colour",
+		},
+		{
+			name:    "missing provider",
+			args:    []string{"list-vaults"},
+			code:    ExitUsage,
+			wantErr: "--provider is required",
+		},
+		{
+			name:    "unknown provider",
+			args:    []string{"list-vaults", "-p", "nosuch"},
+			code:    ExitError,
+			wantErr: "unknown provider: nosuch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d; stderr:\n%s", code, tt.code, stderr)
+			}
+			if !strings.Contains(stderr, tt.wantErr) {
+				t.Errorf("stderr %q does not contain %q", stderr, tt.wantErr)
+			}
+			if stdout != "" {
+				t.Errorf("unexpected output on failure: %q", stdout)
+			}
+		})
+	}
+}
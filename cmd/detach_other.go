@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "syscall"
+
+// detachAttr has no session handling on this platform
+func detachAttr() *syscall.SysProcAttr {
+	return nil
+}
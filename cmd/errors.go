@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// Exit codes returned by the CLI
+// Scripts (e.g. browse-secrets.sh) rely on these values, so they must stay stable.
+const (
+	ExitOK               = 0 // Success
+	ExitError            = 1 // Unclassified error
+	ExitUsage            = 2 // Invalid flags or arguments
+	ExitNotFound         = 3 // Vault or secret not found
+	ExitPermissionDenied = 4 // Authenticated but not authorized
+	ExitAuthExpired      = 5 // Missing, invalid or expired credentials
+	ExitSealed           = 6 // Vault is sealed
+	ExitUnavailable      = 7 // Backend unreachable or overloaded
+	ExitFindings         = 8 // An audit found secrets breaching its thresholds
+)
+
+// usageError marks errors caused by invalid command-line usage
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// newUsageError creates a usage error with a formatted message
+func newUsageError(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// findingsError reports that an audit completed but found problems
+type findingsError struct {
+	summary string
+}
+
+func (e *findingsError) Error() string { return e.summary }
+
+// newFindingsError creates a findings error with a formatted summary
+func newFindingsError(format string, args ...interface{}) error {
+	return &findingsError{summary: fmt.Sprintf(format, args...)}
+}
+
+// exitCode maps an error to the documented exit code
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return ExitUsage
+	}
+
+	var findingsErr *findingsError
+	if errors.As(err, &findingsErr) {
+		return ExitFindings
+	}
+
+	switch provider.KindOf(err) {
+	case provider.ErrNotFound:
+		return ExitNotFound
+	case provider.ErrPermissionDenied:
+		return ExitPermissionDenied
+	case provider.ErrAuthExpired:
+		return ExitAuthExpired
+	case provider.ErrSealed:
+		return ExitSealed
+	case provider.ErrUnavailable:
+		return ExitUnavailable
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import "github.com/ylchen07/smart-keyvault/pkg/models"
+
+// showSecret reports whether a listed secret passes the configured filters
+// Disabled secrets are hidden unless filters.enabled_only is false.
+func showSecret(s *models.Secret) bool {
+	return s.Enabled || !appConfig.Filters.EnabledOnly
+}
+
+// filterSecrets drops secrets hidden by the configured filters, in place
+func filterSecrets(secrets []*models.Secret) []*models.Secret {
+	kept := secrets[:0]
+	for _, s := range secrets {
+		if showSecret(s) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
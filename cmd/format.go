@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/output"
+)
+
+var (
+	outputColumns []string // --columns selection for table output
+	noHeaders     bool     // --no-headers for table output
+	sortKey       string   // --sort key for listings
+)
+
+// addOutputFlags registers the output format flags shared by listing commands
+func addOutputFlags(cmd *cobra.Command, defaultFormat string) {
+	cmd.Flags().StringVarP(&formatType, "format", "f", defaultFormat, "Output format (plain, json, table, yaml, ndjson)")
+	cmd.Flags().StringSliceVar(&outputColumns, "columns", nil, "Comma-separated columns for table output (e.g. name,provider,location,resourceGroup)")
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "Omit the header row in table output")
+}
+
+// newFormatter returns the formatter selected by the output flags
+func newFormatter() (output.Formatter, error) {
+	return output.GetFormatter(output.Format(formatType), output.Options{
+		Columns:   outputColumns,
+		NoHeaders: noHeaders,
+	})
+}
+
+// addSortFlag registers the --sort flag for listing commands
+func addSortFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sortKey, "sort", "", "Sort results by name, vault or updated (default: provider order, sorted by name)")
+}
+
+// validateSortKey checks the --sort flag before any provider call
+func validateSortKey() error {
+	if err := output.ValidateSortKey(sortKey); err != nil {
+		return newUsageError("%v", err)
+	}
+	return nil
+}
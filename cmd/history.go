@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/history"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+var recentLimit int
+
+// historyPath returns the location of the usage history file
+func historyPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, history.DefaultFileName), nil
+}
+
+// loadHistory opens the usage history store
+func loadHistory() (*history.Store, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	return history.Load(path)
+}
+
+// recordUsage adds a secret reference to the usage history
+// Failures are reported as warnings so they never break secret retrieval.
+func recordUsage(ref models.SecretRef) {
+	if appConfig != nil && !appConfig.History.Enabled {
+		return
+	}
+
+	store, err := loadHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load history: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	store.Record(ref, now)
+
+	maxEntries := history.DefaultMaxEntries
+	if appConfig != nil && appConfig.History.MaxEntries > 0 {
+		maxEntries = appConfig.History.MaxEntries
+	}
+	store.Prune(maxEntries, now)
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+	}
+}
+
+// favoriteRefs returns the pinned favorites from config as secret references
+// Favorites without an instance resolve to the provider's default instance.
+func favoriteRefs() []models.SecretRef {
+	refs := make([]models.SecretRef, 0, len(appConfig.Favorites))
+	for _, fav := range appConfig.Favorites {
+		instance := fav.Instance
+		if instance == "" {
+			cfg, err := getInstanceConfig(fav.Provider, "")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping favorite %s/%s: %v\n", fav.Vault, fav.Secret, err)
+				continue
+			}
+			instance = cfg.Instance
+		}
+
+		refs = append(refs, models.SecretRef{
+			Provider: fav.Provider,
+			Instance: instance,
+			Vault:    fav.Vault,
+			Secret:   fav.Secret,
+		})
+	}
+	return refs
+}
+
+// recentCmd returns the recent command
+func recentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "List favorite and recently used secrets",
+		Long:  `List pinned favorites followed by recently and frequently used secrets, one provider/instance/vault/secret reference per line for fzf.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Load config
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			store, err := loadHistory()
+			if err != nil {
+				return err
+			}
+
+			// Favorites first, then history by frecency, without duplicates
+			seen := make(map[models.SecretRef]bool)
+			var refs []models.SecretRef
+
+			for _, ref := range favoriteRefs() {
+				if !seen[ref] {
+					seen[ref] = true
+					refs = append(refs, ref)
+				}
+			}
+
+			for _, entry := range store.Ranked(time.Now()) {
+				if !seen[entry.SecretRef] {
+					seen[entry.SecretRef] = true
+					refs = append(refs, entry.SecretRef)
+				}
+			}
+
+			if recentLimit > 0 && len(refs) > recentLimit {
+				refs = refs[:recentLimit]
+			}
+
+			for _, ref := range refs {
+				fmt.Println(ref.String())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&recentLimit, "limit", "l", 50, "Maximum number of references to output (0 for no limit)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// historyCmd returns the history command
+func historyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Manage the local secret usage history",
+	}
+
+	cmd.AddCommand(historyListCmd())
+	cmd.AddCommand(historyClearCmd())
+	return cmd
+}
+
+// historyListCmd returns the history list command
+func historyListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded secret references ranked by frecency",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := loadHistory()
+			if err != nil {
+				return err
+			}
+
+			entries := store.Ranked(time.Now())
+
+			switch formatType {
+			case "json":
+				data, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			case "plain":
+				// Format: reference<TAB>count<TAB>last used
+				for _, e := range entries {
+					fmt.Printf("%s\t%d\t%s\n", e.String(), e.Count, e.LastUsed.Local().Format(time.RFC3339))
+				}
+			default:
+				return fmt.Errorf("unsupported format: %s", formatType)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	return cmd
+}
+
+// historyClearCmd returns the history clear command
+func historyClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all recorded secret references",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := loadHistory()
+			if err != nil {
+				return err
+			}
+
+			store.Clear()
+			if err := store.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "History cleared")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/lint"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// lintCmd returns the lint command
+func lintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Scan secret values for hygiene problems",
+		Long: `Walk the vaults of every enabled provider instance (or those selected with
+--provider and --instance) and flag likely problems:
+
+  empty                     empty values
+  whitespace                leading/trailing whitespace or newlines
+  duplicate                 identical values in more than one secret (compared by SHA-256)
+  weak_password             short, single-class or common values of password-like secrets
+  private_key_content_type  PEM private keys stored without a content type
+  disabled_referenced       disabled secrets still used by an alias or favorite
+
+Values are never printed. Rules can be switched off under lint.rules in the
+config file. Exits with code 8 when any issue is found.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			if formatType != "table" && formatType != "json" {
+				return newUsageError("unsupported format: %s (use table or json)", formatType)
+			}
+
+			linter, err := lint.New(lint.Options{
+				Rules:     appConfig.Lint.Rules,
+				MinLength: appConfig.Lint.MinLength,
+			})
+			if err != nil {
+				return fmt.Errorf("invalid lint config: %w", err)
+			}
+
+			instances := targetInstances()
+			if len(instances) == 0 {
+				return fmt.Errorf("no enabled provider instances configured")
+			}
+
+			references := secretReferences()
+			ctx := context.Background()
+
+			for _, inst := range instances {
+				p, _, err := openProvider(inst.Provider, inst.Name)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s/%s: %v\n", inst.Provider, inst.Name, err)
+					continue
+				}
+
+				err = walkSecrets(ctx, inst, p, vaultName, func(vault *models.Vault, secret *models.Secret, value *models.SecretValue) error {
+					ref := models.SecretRef{
+						Provider: inst.Provider,
+						Instance: inst.Name,
+						Vault:    vault.Name,
+						Secret:   secret.Name,
+					}
+
+					if value == nil {
+						linter.CheckDisabled(ref, references[ref])
+						return nil
+					}
+					linter.CheckValue(ref, value)
+					return nil
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s/%s: %v\n", inst.Provider, inst.Name, err)
+				}
+			}
+
+			issues := linter.Issues()
+			if len(issues) == 0 {
+				if formatType == "json" {
+					fmt.Println("[]")
+				} else {
+					fmt.Fprintln(os.Stderr, "No issues found")
+				}
+				return nil
+			}
+
+			result, err := lint.FormatIssues(issues, formatType)
+			if err != nil {
+				return err
+			}
+			fmt.Println(result)
+
+			return newFindingsError("%d issue(s) found in %d secret(s)", len(issues), countSecrets(issues))
+		},
+	}
+
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Only lint this provider (default: all enabled providers)")
+	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Only lint this instance (default: all instances)")
+	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Only lint vaults with this name")
+	cmd.Flags().StringVarP(&formatType, "format", "f", "table", "Output format (table, json)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// secretReferences maps secrets used by aliases and favorites to a
+// description of each reference (e.g. "alias prod-db")
+func secretReferences() map[models.SecretRef][]string {
+	refs := make(map[models.SecretRef][]string)
+
+	for name, alias := range appConfig.Aliases {
+		instance := alias.Instance
+		if instance == "" {
+			cfg, err := getInstanceConfig(alias.Provider, "")
+			if err != nil {
+				continue
+			}
+			instance = cfg.Instance
+		}
+
+		ref := models.SecretRef{Provider: alias.Provider, Instance: instance, Vault: alias.Vault, Secret: alias.Secret}
+		refs[ref] = append(refs[ref], "alias "+name)
+	}
+
+	for _, ref := range favoriteRefs() {
+		refs[ref] = append(refs[ref], "favorite")
+	}
+
+	for ref := range refs {
+		sort.Strings(refs[ref])
+	}
+	return refs
+}
+
+// countSecrets returns the number of distinct secrets with issues
+func countSecrets(issues []lint.Issue) int {
+	seen := make(map[models.SecretRef]bool)
+	for _, issue := range issues {
+		seen[issue.SecretRef] = true
+	}
+	return len(seen)
+}
@@ -2,27 +2,47 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/aws"
 	"github.com/ylchen07/smart-keyvault/internal/azure"
+	"github.com/ylchen07/smart-keyvault/internal/bitwarden"
 	"github.com/ylchen07/smart-keyvault/internal/clipboard"
 	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/file"
+	"github.com/ylchen07/smart-keyvault/internal/gcp"
 	"github.com/ylchen07/smart-keyvault/internal/hashicorp"
+	"github.com/ylchen07/smart-keyvault/internal/history"
+	"github.com/ylchen07/smart-keyvault/internal/kubernetes"
+	"github.com/ylchen07/smart-keyvault/internal/lint"
+	"github.com/ylchen07/smart-keyvault/internal/memory"
+	"github.com/ylchen07/smart-keyvault/internal/onepassword"
 	"github.com/ylchen07/smart-keyvault/internal/output"
+	"github.com/ylchen07/smart-keyvault/internal/pass"
+	"github.com/ylchen07/smart-keyvault/internal/plugin"
 	"github.com/ylchen07/smart-keyvault/internal/provider"
 	"github.com/ylchen07/smart-keyvault/pkg/models"
 )
 
 var (
-	providerName string
-	instanceName string // New: instance name for multi-instance providers
-	vaultName    string
-	secretName   string
-	formatType   string
-	copyToClip   bool
-	configPath   string // New: optional config file path
+	providerName  string
+	instanceName  string // New: instance name for multi-instance providers
+	vaultName     string
+	secretName    string
+	secretRef     string // provider/instance/vault/secret reference (e.g. from `recent`)
+	fieldName     string // field of a multi-field secret
+	secretVersion string // --version for get-secret
+	formatType    string
+	copyToClip    bool
+	maskMode      string // --mask for get-secret
+	valueEncoding string // --encoding for get-secret
+	noNewline     bool   // --no-newline for get-secret
+	configPath    string // New: optional config file path
 
 	// Global config loaded once
 	appConfig *config.Config
@@ -30,8 +50,17 @@ var (
 
 func init() {
 	// Register providers
-	provider.Register("azure", azure.NewProvider)
-	provider.Register("hashicorp", hashicorp.NewProvider)
+	provider.Register("azure", azure.NewProvider, azure.Schema)
+	provider.Register("hashicorp", hashicorp.NewProvider, hashicorp.Schema)
+	provider.Register("memory", memory.NewProvider, memory.Schema)
+	provider.Register("file", file.NewProvider, file.Schema)
+	provider.Register("pass", pass.NewProvider, pass.Schema)
+	provider.Register("kubernetes", kubernetes.NewProvider, kubernetes.Schema)
+	provider.Register("secretsmanager", aws.NewSecretsManagerProvider, aws.SecretsManagerSchema)
+	provider.Register("ssm", aws.NewSSMProvider, aws.SSMSchema)
+	provider.Register("gcp", gcp.NewProvider, gcp.Schema)
+	provider.Register("onepassword", onepassword.NewProvider, onepassword.Schema)
+	provider.Register("bitwarden", bitwarden.NewProvider, bitwarden.Schema)
 }
 
 // loadConfig loads the application config
@@ -44,19 +73,43 @@ func loadConfig() error {
 		appConfig, err = config.Load()
 	}
 
-	// If config file not found, create a minimal default config
+	// If the config file is missing or invalid, fall back to a minimal default
+	// config; an invalid file is worth a warning, a missing one is not
 	if err != nil {
+		defaultPath, _ := config.DefaultConfigPath()
+		if _, statErr := os.Stat(defaultPath); configPath != "" || statErr == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 		appConfig = &config.Config{
 			Defaults: config.Defaults{},
 			Providers: config.Providers{
-				Azure:     &config.AzureConfig{Enabled: true, Instances: []config.AzureInstance{}},
-				Hashicorp: &config.HashicorpConfig{Enabled: true, Instances: []config.HashicorpInstance{}},
+				"azure":     {Enabled: true},
+				"hashicorp": {Enabled: true},
+			},
+			FZF:      config.FZFConfig{Height: "40%", Border: "rounded", Preview: false},
+			Filters:  config.Filters{EnabledOnly: true},
+			History:  config.HistoryConfig{Enabled: true, MaxEntries: history.DefaultMaxEntries},
+			Lint:     config.LintConfig{MinLength: lint.DefaultMinLength},
+			AuditLog: config.AuditLogConfig{Enabled: true},
+			Agent: config.AgentConfig{
+				Enabled:     true,
+				IdleTimeout: 30 * time.Minute,
+				MetadataTTL: time.Minute,
+				Cache:       config.ValueCache{TTL: 5 * time.Minute, MaxEntries: 100},
 			},
-			FZF:     config.FZFConfig{Height: "40%", Border: "rounded", Preview: false},
-			Filters: config.Filters{EnabledOnly: true},
 		}
 	}
 
+	// Secret references in provider settings are read like get-secret reads
+	appConfig.SetSecretReader(readConfigRef)
+
+	// Plugins on PATH or in the plugin directory become providers too
+	pluginDir := appConfig.Plugins.Dir
+	if pluginDir == "" {
+		pluginDir, _ = config.PluginDir()
+	}
+	plugin.RegisterDiscovered(pluginDir)
+
 	return nil
 }
 
@@ -68,51 +121,39 @@ func getProviderConfig(providerName, instanceName string) (*provider.Config, err
 		}
 	}
 
-	cfg := &provider.Config{
-		Name:     providerName,
-		Settings: make(map[string]interface{}),
+	if appConfig.Providers[providerName] == nil && !provider.IsRegistered(providerName) {
+		return nil, fmt.Errorf("unknown provider: %s", providerName)
 	}
 
-	switch providerName {
-	case "azure":
-		var instance *config.AzureInstance
-		var err error
-
-		if instanceName != "" {
-			instance, err = appConfig.GetAzureInstance(instanceName)
-		} else {
-			instance, err = appConfig.GetDefaultAzureInstance()
-		}
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Azure instance: %w", err)
-		}
-
-		cfg.Settings["subscription_id"] = instance.SubscriptionID
-
-	case "hashicorp":
-		var instance *config.HashicorpInstance
-		var err error
-
-		if instanceName != "" {
-			instance, err = appConfig.GetHashicorpInstance(instanceName)
-		} else {
-			instance, err = appConfig.GetDefaultHashicorpInstance()
-		}
+	// Secret references in the settings are read from their providers here
+	return appConfig.ProviderConfig(context.Background(), providerName, instanceName)
+}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Hashicorp instance: %w", err)
+// getInstanceConfig returns the provider.Config of an instance without its
+// settings, for providers the agent serves with settings it resolves itself
+func getInstanceConfig(providerName, instanceName string) (*provider.Config, error) {
+	if appConfig == nil {
+		if err := loadConfig(); err != nil {
+			return nil, err
 		}
+	}
 
-		cfg.Settings["address"] = instance.Address
-		cfg.Settings["token"] = instance.Token
-		cfg.Settings["namespace"] = instance.Namespace
-
-	default:
+	if appConfig.Providers[providerName] == nil && !provider.IsRegistered(providerName) {
 		return nil, fmt.Errorf("unknown provider: %s", providerName)
 	}
 
-	return cfg, nil
+	var instance *config.Instance
+	var err error
+	if instanceName != "" {
+		instance, err = appConfig.GetInstance(providerName, instanceName)
+	} else {
+		instance, err = appConfig.GetDefaultInstance(providerName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s instance: %w", providerName, err)
+	}
+
+	return &provider.Config{Name: providerName, Instance: instance.Name}, nil
 }
 
 func main() {
@@ -120,18 +161,53 @@ func main() {
 		Use:   "smart-keyvault",
 		Short: "A multi-provider CLI for secret management",
 		Long:  `Smart KeyVault provides a unified interface for browsing and retrieving secrets from Azure KeyVault, Hashicorp Vault, and more.`,
+		// Errors are printed once by main with a documented exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// --format is bound to one variable by every command, so the last
+			// registered default wins; re-apply this command's own default
+			if f := cmd.Flags().Lookup("format"); f != nil && !f.Changed {
+				formatType = f.DefValue
+			}
+
+			// Remember the subcommand (e.g. "audit expiry") for the audit log and policies
+			commandName = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		},
 	}
 
+	// Flag parsing errors are usage errors (exit code 2)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
 	// Add commands
 	rootCmd.AddCommand(listProvidersCmd())
 	rootCmd.AddCommand(listVaultsCmd())
 	rootCmd.AddCommand(listSecretsCmd())
 	rootCmd.AddCommand(getSecretCmd())
+	rootCmd.AddCommand(setSecretCmd())
 	rootCmd.AddCommand(walkSecretsCmd())
+	rootCmd.AddCommand(recentCmd())
+	rootCmd.AddCommand(historyCmd())
+	rootCmd.AddCommand(aliasCmd())
+	rootCmd.AddCommand(auditCmd())
+	rootCmd.AddCommand(lintCmd())
+	rootCmd.AddCommand(agentCmd())
+	rootCmd.AddCommand(cacheCmd())
+	rootCmd.AddCommand(serveCmd())
+
+	err := rootCmd.Execute()
+	closeAuditLog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintln(os.Stderr, "Run 'smart-keyvault --help' for usage.")
+		}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -141,11 +217,15 @@ func listProvidersCmd() *cobra.Command {
 		Use:   "list-providers",
 		Short: "List available secret providers",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Loading the config discovers plugin providers
+			if err := loadConfig(); err != nil {
+				return err
+			}
+
 			providers := provider.ListProviders()
 
 			// Get formatter
-			format := output.Format(formatType)
-			formatter, err := output.GetFormatter(format)
+			formatter, err := newFormatter()
 			if err != nil {
 				return err
 			}
@@ -161,7 +241,7 @@ func listProvidersCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	addOutputFlags(cmd, "plain")
 	return cmd
 }
 
@@ -176,32 +256,50 @@ func listVaultsCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			// Get provider config
-			cfg, err := getProviderConfig(providerName, instanceName)
-			if err != nil {
+			if err := validateSortKey(); err != nil {
 				return err
 			}
 
-			// Get provider
-			p, err := provider.GetProvider(providerName, cfg)
-			if err != nil {
-				return err
+			ctx := context.Background()
+
+			if !queryAll && providerName == "" {
+				return newUsageError("--provider is required unless --all is set")
 			}
 
-			// List vaults
-			ctx := context.Background()
-			vaults, err := p.ListVaults(ctx)
-			if err != nil {
-				return err
+			// NDJSON is streamed page by page instead of buffered
+			if streamListing() {
+				return streamVaults(ctx)
+			}
+
+			// List vaults from one instance, or from every enabled instance
+			var vaults []*models.Vault
+			if queryAll {
+				allVaults, err := listAllVaults(ctx)
+				if err != nil {
+					return err
+				}
+				vaults = allVaults
+			} else {
+				// Get provider, guarded by the configured policies
+				p, _, err := openProvider(providerName, instanceName)
+				if err != nil {
+					return err
+				}
+
+				vaults, err = p.ListVaults(ctx)
+				if err != nil {
+					return err
+				}
 			}
 
 			// Get formatter
-			format := output.Format(formatType)
-			formatter, err := output.GetFormatter(format)
+			formatter, err := newFormatter()
 			if err != nil {
 				return err
 			}
 
+			output.SortVaults(vaults, sortKey)
+
 			// Format and output
 			result, err := formatter.FormatVaults(vaults)
 			if err != nil {
@@ -213,11 +311,12 @@ func listVaultsCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp)")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
 	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
-	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	addOutputFlags(cmd, "plain")
 	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
-	cmd.MarkFlagRequired("provider")
+	cmd.Flags().BoolVarP(&queryAll, "all", "a", false, "Query every enabled provider and instance concurrently")
+	addSortFlag(cmd)
 	return cmd
 }
 
@@ -232,32 +331,51 @@ func listSecretsCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			// Get provider config
-			cfg, err := getProviderConfig(providerName, instanceName)
-			if err != nil {
+			if err := validateSortKey(); err != nil {
 				return err
 			}
 
-			// Get provider
-			p, err := provider.GetProvider(providerName, cfg)
-			if err != nil {
-				return err
+			ctx := context.Background()
+
+			if !queryAll && (providerName == "" || vaultName == "") {
+				return newUsageError("--provider and --vault are required unless --all is set")
 			}
 
-			// List secrets
-			ctx := context.Background()
-			secrets, err := p.ListSecrets(ctx, vaultName)
-			if err != nil {
-				return err
+			// NDJSON is streamed page by page instead of buffered
+			if streamListing() {
+				return streamSecrets(ctx)
+			}
+
+			// List secrets from one vault, or from every enabled instance
+			var secrets []*models.Secret
+			if queryAll {
+				allSecrets, err := listAllSecrets(ctx, vaultName)
+				if err != nil {
+					return err
+				}
+				secrets = allSecrets
+			} else {
+				// Get provider, guarded by the configured policies
+				p, _, err := openProvider(providerName, instanceName)
+				if err != nil {
+					return err
+				}
+
+				secrets, err = p.ListSecrets(ctx, vaultName)
+				if err != nil {
+					return err
+				}
+				secrets = filterSecrets(secrets)
 			}
 
 			// Get formatter
-			format := output.Format(formatType)
-			formatter, err := output.GetFormatter(format)
+			formatter, err := newFormatter()
 			if err != nil {
 				return err
 			}
 
+			output.SortSecrets(secrets, sortKey)
+
 			// Format and output
 			result, err := formatter.FormatSecrets(secrets)
 			if err != nil {
@@ -269,73 +387,162 @@ func listSecretsCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp)")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
 	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
-	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name")
-	cmd.Flags().StringVarP(&formatType, "format", "f", "plain", "Output format (plain, json)")
+	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name (optional with --all, restricts to vaults with this name)")
+	addOutputFlags(cmd, "plain")
 	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
-	cmd.MarkFlagRequired("provider")
-	cmd.MarkFlagRequired("vault")
+	cmd.Flags().BoolVarP(&queryAll, "all", "a", false, "Query every vault of every enabled provider and instance concurrently")
+	addSortFlag(cmd)
 	return cmd
 }
 
 // getSecretCmd returns the get-secret command
 func getSecretCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "get-secret",
+		Use:   "get-secret [alias]",
 		Short: "Get a secret value",
+		Long:  `Get a secret value by alias, by --ref, or by --provider, --vault and --name.`,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Load config
 			if err := loadConfig(); err != nil {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			// Get provider config
-			cfg, err := getProviderConfig(providerName, instanceName)
-			if err != nil {
-				return err
+			// An alias or reference overrides the individual location flags
+			if len(args) == 1 {
+				alias, err := appConfig.GetAlias(args[0])
+				if err != nil {
+					return err
+				}
+				providerName, instanceName, vaultName, secretName = alias.Provider, alias.Instance, alias.Vault, alias.Secret
+				if fieldName == "" {
+					fieldName = alias.Field
+				}
+			} else if secretRef != "" {
+				ref, err := models.ParseSecretRef(secretRef)
+				if err != nil {
+					return err
+				}
+				providerName, instanceName, vaultName, secretName = ref.Provider, ref.Instance, ref.Vault, ref.Secret
 			}
 
-			// Get provider
-			p, err := provider.GetProvider(providerName, cfg)
+			if providerName == "" || vaultName == "" || secretName == "" {
+				return newUsageError("either an alias, --ref, or --provider, --vault and --name are required")
+			}
+
+			// Get provider, guarded by the configured policies
+			p, cfg, err := openProvider(providerName, instanceName)
 			if err != nil {
 				return err
 			}
 
 			// Get secret
 			ctx := context.Background()
-			secret, err := p.GetSecret(ctx, vaultName, secretName)
+			secret, err := readSecret(ctx, p, vaultName, secretName, secretVersion)
+
+			// Log the read (never the value) before anything is disclosed
+			command := commandName
+			if copyToClip {
+				command = "copy"
+			}
+			ref := models.SecretRef{
+				Provider: providerName,
+				Instance: cfg.Instance,
+				Vault:    vaultName,
+				Secret:   secretName,
+			}
+			if logErr := recordAccess(command, ref, fieldName, err); logErr != nil {
+				return logErr
+			}
 			if err != nil {
 				return err
 			}
 
+			// Select a single field of a multi-field secret
+			if fieldName != "" {
+				value, ok := secret.Fields[fieldName]
+				if !ok {
+					return provider.NewError(provider.ErrNotFound, fmt.Errorf("secret '%s' has no field '%s'", secretName, fieldName))
+				}
+				// Output only the selected field, never the others
+				secret.Value = value
+				secret.Fields = nil
+			}
+
+			// Remember the reference (never the value) for quick mode
+			recordUsage(ref)
+
 			// Copy to clipboard if requested
 			if copyToClip {
 				if err := clipboard.Copy(secret.Value); err != nil {
 					return fmt.Errorf("failed to copy to clipboard: %w", err)
 				}
 				fmt.Fprintf(os.Stderr, "Secret '%s' copied to clipboard!\n", secretName)
-			} else {
-				// Output the secret value
-				fmt.Println(secret.Value)
+				return nil
+			}
+
+			// Encode and mask before formatting so every format shows the same value
+			display, err := output.TransformValue(secret, output.ValueOptions{
+				Mask:     maskMode,
+				Encoding: valueEncoding,
+			})
+			if err != nil {
+				return newUsageError("%v", err)
 			}
 
+			// Get formatter
+			formatter, err := newFormatter()
+			if err != nil {
+				return err
+			}
+
+			// Format and output
+			result, err := formatter.FormatSecretValue(display)
+			if err != nil {
+				return err
+			}
+
+			if noNewline {
+				fmt.Print(result)
+			} else {
+				fmt.Println(result)
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp)")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
 	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
 	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name")
 	cmd.Flags().StringVarP(&secretName, "name", "n", "", "Secret name")
+	cmd.Flags().StringVarP(&secretRef, "ref", "r", "", "Secret reference as provider/instance/vault/secret (replaces --provider, --instance, --vault and --name)")
+	cmd.Flags().StringVar(&fieldName, "field", "", "Field to return from a multi-field secret (e.g. Vault KV keys)")
+	cmd.Flags().StringVar(&secretVersion, "version", "", "Secret version to read (default: current)")
 	cmd.Flags().BoolVarP(&copyToClip, "copy", "c", false, "Copy secret to clipboard")
+	cmd.Flags().StringVar(&maskMode, "mask", "", "Mask the printed value: length (show only its length) or last4 (show only the last 4 characters)")
+	cmd.Flags().StringVar(&valueEncoding, "encoding", "raw", "Encoding of the printed value (raw, base64, hex)")
+	cmd.Flags().BoolVarP(&noNewline, "no-newline", "N", false, "Do not print a trailing newline (for piping values)")
 	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
-	cmd.MarkFlagRequired("provider")
-	cmd.MarkFlagRequired("vault")
-	cmd.MarkFlagRequired("name")
+	addOutputFlags(cmd, "plain")
 	return cmd
 }
 
+// readSecret reads the current secret value, or a specific version when
+// version is set and the provider supports versioning
+func readSecret(ctx context.Context, p provider.Provider, vault, secret, version string) (*models.SecretValue, error) {
+	if version == "" {
+		return p.GetSecret(ctx, vault, secret)
+	}
+
+	versioner, ok := p.(provider.Versioner)
+	if !ok || !p.SupportsFeature(provider.FeatureVersioning) {
+		return nil, newUsageError("provider %s does not support reading secret versions", p.Name())
+	}
+	return versioner.GetSecretVersion(ctx, vault, secret, version)
+}
+
 // walkSecretsCmd returns the walk-secrets command
 func walkSecretsCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -348,66 +555,54 @@ func walkSecretsCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			// Get provider config
-			cfg, err := getProviderConfig(providerName, instanceName)
+			if err := validateSortKey(); err != nil {
+				return err
+			}
+
+			// Get provider, guarded by the configured policies
+			p, cfg, err := openProvider(providerName, instanceName)
 			if err != nil {
 				return err
 			}
 
-			// Get provider
-			p, err := provider.GetProvider(providerName, cfg)
+			// Get formatter
+			formatter, err := newFormatter()
 			if err != nil {
 				return err
 			}
 
 			ctx := context.Background()
 
-			// Determine which vaults to process
-			var vaults []*models.Vault
-			if vaultName != "" {
-				// Single vault specified
-				vaults = []*models.Vault{{Name: vaultName}}
-			} else {
-				// Get all vaults
-				allVaults, err := p.ListVaults(ctx)
-				if err != nil {
-					return fmt.Errorf("failed to list vaults: %w", err)
-				}
-				vaults = allVaults
+			// NDJSON is streamed as values arrive instead of buffered
+			var stream *output.NDJSONWriter
+			if output.Format(formatType) == output.FormatNDJSON {
+				stream = output.NewNDJSONWriter(os.Stdout)
 			}
 
 			// Walk through each vault and collect all secrets with values
 			secretsByVault := make(map[string][]*models.SecretValue)
 
-			for _, vault := range vaults {
-				// List secrets in vault
-				secrets, err := p.ListSecrets(ctx, vault.Name)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to list secrets in vault %s: %v\n", vault.Name, err)
-					continue
+			inst := config.InstanceRef{Provider: providerName, Name: cfg.Instance}
+			err = walkSecrets(ctx, inst, p, vaultName, func(vault *models.Vault, secret *models.Secret, value *models.SecretValue) error {
+				// Disabled secrets have no readable value
+				if value == nil {
+					return nil
 				}
 
-				// Get value for each secret
-				var secretValues []*models.SecretValue
-				for _, secret := range secrets {
-					secretValue, err := p.GetSecret(ctx, vault.Name, secret.Name)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: failed to get secret %s in vault %s: %v\n", secret.Name, vault.Name, err)
-						continue
-					}
-					secretValues = append(secretValues, secretValue)
+				if stream != nil {
+					return stream.Write(value)
 				}
-
-				secretsByVault[vault.Name] = secretValues
-			}
-
-			// Get formatter
-			format := output.Format(formatType)
-			formatter, err := output.GetFormatter(format)
+				secretsByVault[vault.Name] = append(secretsByVault[vault.Name], value)
+				return nil
+			})
 			if err != nil {
 				return err
 			}
 
+			if stream != nil {
+				return nil
+			}
+
 			// Format and output
 			result, err := formatter.FormatWalkSecrets(secretsByVault)
 			if err != nil {
@@ -419,10 +614,11 @@ func walkSecretsCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp)")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
 	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
 	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name (optional - if not specified, walks all vaults)")
-	cmd.Flags().StringVarP(&formatType, "format", "f", "json", "Output format (plain, json)")
+	addOutputFlags(cmd, "json")
+	addSortFlag(cmd)
 	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
 	cmd.MarkFlagRequired("provider")
 	return cmd
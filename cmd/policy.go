@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ylchen07/smart-keyvault/internal/agent"
+	"github.com/ylchen07/smart-keyvault/internal/policy"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// confirmEnv confirms policy prompts non-interactively when set to 1, true or yes
+const confirmEnv = "SMART_KEYVAULT_CONFIRM"
+
+var (
+	// walkCounter totals the secrets listed by this run across all instances
+	walkCounter = &policy.WalkCounter{}
+
+	// confirmMu serializes prompts from instances queried in parallel
+	confirmMu sync.Mutex
+)
+
+// openProvider creates the provider for an instance, guarded by the
+// configured policies for the running command. Every command goes through
+// here so policies are enforced before any provider call; the provider is
+// served by the agent when one is running.
+func openProvider(name, instance string) (provider.Provider, *provider.Config, error) {
+	return openProviderFor(policyCommand(), confirmPolicy, walkCounter, name, instance)
+}
+
+// openProviderFor is openProvider for an explicit command, confirmation
+// handler and walk counter, for callers serving several commands (e.g. serve)
+func openProviderFor(command string, confirm policy.ConfirmFunc, walked *policy.WalkCounter, name, instance string) (provider.Provider, *provider.Config, error) {
+	p, cfg, err := newProvider(name, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+	return guardProvider(p, cfg, command, confirm, walked)
+}
+
+// newProvider creates the unguarded provider for an instance, using the
+// agent's warm provider when one is running
+func newProvider(name, instance string) (provider.Provider, *provider.Config, error) {
+	cfg, err := getInstanceConfig(name, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The agent resolves the instance's secret references itself
+	if client := agentClient(); client != nil {
+		return agent.NewRemoteProvider(client, name, cfg.Instance), cfg, nil
+	}
+
+	cfg, err = getProviderConfig(name, cfg.Instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := provider.GetProvider(name, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, cfg, nil
+}
+
+// guardProvider wraps p in the configured policies for command
+func guardProvider(p provider.Provider, cfg *provider.Config, command string, confirm policy.ConfirmFunc, walked *policy.WalkCounter) (provider.Provider, *provider.Config, error) {
+	engine, err := policy.New(appConfig.Policies)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid policies: %w", err)
+	}
+
+	return policy.Wrap(p, engine, command, cfg.Name, cfg.Instance, confirm, walked), cfg, nil
+}
+
+// readConfigRef reads a ${secret:...} reference in provider settings with
+// the policies and audit log of get-secret, under the command "config-ref"
+func readConfigRef(ctx context.Context, cfg *provider.Config, ref models.SecretRef, field string) (*models.SecretValue, error) {
+	p, err := provider.GetProvider(cfg.Name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+	if closer, ok := p.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	guarded, _, err := guardProvider(p, cfg, "config-ref", confirmPolicy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := guarded.GetSecret(ctx, ref.Vault, ref.Secret)
+	if logErr := recordAccess("config-ref", ref, field, err); logErr != nil {
+		return nil, logErr
+	}
+	return secret, err
+}
+
+// policyCommand returns the command name policies are matched against
+// get-secret --copy counts as "copy".
+func policyCommand() string {
+	if commandName == "get-secret" && copyToClip {
+		return "copy"
+	}
+	return commandName
+}
+
+// confirmFromEnv accepts confirmation only from $SMART_KEYVAULT_CONFIRM, for
+// callers that cannot prompt
+func confirmFromEnv(prompt string) error {
+	switch strings.ToLower(os.Getenv(confirmEnv)) {
+	case "1", "true", "yes":
+		return nil
+	}
+	return fmt.Errorf("confirmation required; set %s=1 to confirm non-interactively", confirmEnv)
+}
+
+// confirmPolicy asks for confirmation on the terminal, or accepts it from
+// $SMART_KEYVAULT_CONFIRM when running non-interactively
+func confirmPolicy(prompt string) error {
+	switch strings.ToLower(os.Getenv(confirmEnv)) {
+	case "1", "true", "yes":
+		return nil
+	}
+
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+
+	// Use the terminal directly: stdout is often captured (e.g. by the tmux scripts)
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("confirmation required; set %s=1 to confirm non-interactively", confirmEnv)
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "%s. Continue? [y/N] ", prompt)
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("not confirmed")
+	}
+}
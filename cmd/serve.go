@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/smart-keyvault/internal/output"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	serveListen    string // --listen for serve
+	serveSocket    string // --socket for serve
+	serveTokenFile string // --token-file for serve
+
+	// serveProviders holds the providers opened by API requests
+	serveProviders = &providerCache{}
+)
+
+// serveCmd returns the serve command
+func serveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local HTTP API for editors and scripts",
+		Long: `Serve a local JSON API so editor plugins and scripts can query secrets without
+shelling out. The API listens on a loopback address (--listen) or a Unix
+socket (--socket) and requires the bearer token generated for this session,
+which is printed on stdout together with the address:
+
+  {"address":"http://127.0.0.1:8787","token":"..."}
+
+Endpoints (GET, responses have the same shapes as --format json):
+
+  /v1/providers
+  /v1/vaults    ?provider=&instance=        (all instances when provider is empty)
+  /v1/secrets   ?provider=&instance=&vault= (all instances when provider is empty)
+  /v1/secret    ?ref= | ?alias= | ?provider=&instance=&vault=&name=  [&version=&field=&mask=&encoding=]
+  /v1/search    ?q=&provider=&instance=&vault=
+
+Policies apply as for the CLI commands list-vaults, list-secrets, get-secret
+and search; confirmations can only be given with SMART_KEYVAULT_CONFIRM=1.
+Secret reads are recorded in the audit log as "serve get-secret".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			if cmd.Flags().Changed("listen") && serveSocket != "" {
+				return newUsageError("--listen and --socket are mutually exclusive")
+			}
+
+			token, err := newSessionToken()
+			if err != nil {
+				return err
+			}
+			if serveTokenFile != "" {
+				if err := writeTokenFile(serveTokenFile, token); err != nil {
+					return err
+				}
+				defer os.Remove(serveTokenFile)
+			}
+
+			l, address, err := serveListener()
+			if err != nil {
+				return err
+			}
+			defer serveProviders.Close()
+
+			server := &http.Server{
+				Handler:           requireToken(token, apiHandler()),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			// Shut down cleanly (removing the socket) when interrupted
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-signals
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(ctx)
+			}()
+
+			info, err := json.Marshal(map[string]string{"address": address, "token": token})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(info))
+			fmt.Fprintf(os.Stderr, "Serving on %s\n", address)
+
+			if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8787", "Loopback address to listen on")
+	cmd.Flags().StringVar(&serveSocket, "socket", "", "Unix socket to listen on instead of --listen")
+	cmd.Flags().StringVar(&serveTokenFile, "token-file", "", "Also write the session token to this file (mode 0600, removed on exit)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// newSessionToken returns a random bearer token for one serve session
+func newSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeTokenFile writes the session token readable by the user only
+func writeTokenFile(path, token string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+// serveListener listens on the Unix socket or loopback address and returns
+// the address clients should use
+func serveListener() (net.Listener, string, error) {
+	if serveSocket != "" {
+		if err := os.MkdirAll(filepath.Dir(serveSocket), 0o700); err != nil {
+			return nil, "", fmt.Errorf("failed to create socket directory: %w", err)
+		}
+		if info, err := os.Lstat(serveSocket); err == nil && info.Mode().Type() != os.ModeSocket {
+			return nil, "", fmt.Errorf("refusing to replace %s: not a socket", serveSocket)
+		}
+		os.Remove(serveSocket)
+
+		l, err := net.Listen("unix", serveSocket)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to listen on %s: %w", serveSocket, err)
+		}
+		if err := os.Chmod(serveSocket, 0o600); err != nil {
+			l.Close()
+			return nil, "", fmt.Errorf("failed to restrict socket: %w", err)
+		}
+		return l, "unix://" + serveSocket, nil
+	}
+
+	host, _, err := net.SplitHostPort(serveListen)
+	if err != nil {
+		return nil, "", newUsageError("invalid --listen address %q: %v", serveListen, err)
+	}
+	if !isLoopback(host) {
+		return nil, "", newUsageError("--listen must be a loopback address (e.g. 127.0.0.1:8787), got %q", serveListen)
+	}
+
+	l, err := net.Listen("tcp", serveListen)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen on %s: %w", serveListen, err)
+	}
+	return l, "http://" + l.Addr().String(), nil
+}
+
+// isLoopback reports whether host names or is a loopback address
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken rejects requests without the session bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiHandler routes the API endpoints
+func apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	formatter := output.NewJSONFormatter()
+
+	mux.HandleFunc("GET /v1/providers", func(w http.ResponseWriter, r *http.Request) {
+		writeFormatted(w, func() (string, error) { return formatter.FormatProviders(provider.ListProviders()) })
+	})
+
+	mux.HandleFunc("GET /v1/vaults", func(w http.ResponseWriter, r *http.Request) {
+		vaults, err := apiListVaults(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeFormatted(w, func() (string, error) { return formatter.FormatVaults(vaults) })
+	})
+
+	mux.HandleFunc("GET /v1/secrets", func(w http.ResponseWriter, r *http.Request) {
+		secrets, err := apiListSecrets(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeFormatted(w, func() (string, error) { return formatter.FormatSecrets(secrets) })
+	})
+
+	mux.HandleFunc("GET /v1/secret", func(w http.ResponseWriter, r *http.Request) {
+		secret, err := apiGetSecret(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeFormatted(w, func() (string, error) { return formatter.FormatSecretValue(secret) })
+	})
+
+	mux.HandleFunc("GET /v1/search", func(w http.ResponseWriter, r *http.Request) {
+		secrets, err := apiSearch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeFormatted(w, func() (string, error) { return formatter.FormatSecrets(secrets) })
+	})
+
+	return mux
+}
+
+// apiOpener opens providers for an API endpoint, enforcing the policies of
+// the equivalent CLI command
+func apiOpener(command string) opener {
+	return func(name, instance string) (provider.Provider, *provider.Config, error) {
+		p, cfg, err := serveProviders.open(name, instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		return guardProvider(p, cfg, command, confirmFromEnv, nil)
+	}
+}
+
+// providerCache keeps the providers opened by serve for the server's
+// lifetime, so connections and plugin processes are reused across requests
+// rather than created (and leaked) by each one
+type providerCache struct {
+	creating singleflight.Group // Providers being created, keyed by provider/instance
+
+	mu        sync.Mutex
+	providers map[string]*cachedProvider // Keyed by provider/instance
+}
+
+// cachedProvider is an unguarded provider and its config
+type cachedProvider struct {
+	provider provider.Provider
+	config   *provider.Config
+}
+
+// open returns the provider for an instance ("" selects the default),
+// creating it on first use
+func (c *providerCache) open(name, instance string) (provider.Provider, *provider.Config, error) {
+	key := name + "/" + instance
+
+	c.mu.Lock()
+	cached, ok := c.providers[key]
+	c.mu.Unlock()
+	if ok {
+		return cached.provider, cached.config, nil
+	}
+
+	v, err, _ := c.creating.Do(key, func() (interface{}, error) {
+		p, cfg, err := newProvider(name, instance)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// Cache under the resolved name too, so default-instance requests share it
+		cached := &cachedProvider{provider: p, config: cfg}
+		if c.providers == nil {
+			c.providers = make(map[string]*cachedProvider)
+		}
+		c.providers[key] = cached
+		c.providers[name+"/"+cfg.Instance] = cached
+		return cached, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	cached = v.(*cachedProvider)
+	return cached.provider, cached.config, nil
+}
+
+// Close closes every cached provider that holds resources
+func (c *providerCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	closed := make(map[*cachedProvider]bool)
+	for _, cached := range c.providers {
+		if closed[cached] {
+			continue
+		}
+		closed[cached] = true
+		if closer, ok := cached.provider.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	c.providers = nil
+	return errors.Join(errs...)
+}
+
+// apiListVaults serves /v1/vaults
+func apiListVaults(r *http.Request) ([]*models.Vault, error) {
+	q := r.URL.Query()
+	open := apiOpener("list-vaults")
+
+	if q.Get("provider") == "" {
+		return listVaultsOf(r.Context(), selectInstances("", q.Get("instance")), open)
+	}
+
+	p, _, err := open(q.Get("provider"), q.Get("instance"))
+	if err != nil {
+		return nil, err
+	}
+	return p.ListVaults(r.Context())
+}
+
+// apiListSecrets serves /v1/secrets
+func apiListSecrets(r *http.Request) ([]*models.Secret, error) {
+	q := r.URL.Query()
+	open := apiOpener("list-secrets")
+
+	if q.Get("provider") == "" {
+		return listSecretsOf(r.Context(), selectInstances("", q.Get("instance")), open, q.Get("vault"))
+	}
+	if q.Get("vault") == "" {
+		return nil, newUsageError("vault is required when provider is set")
+	}
+
+	p, _, err := open(q.Get("provider"), q.Get("instance"))
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := p.ListSecrets(r.Context(), q.Get("vault"))
+	if err != nil {
+		return nil, err
+	}
+	return filterSecrets(secrets), nil
+}
+
+// apiGetSecret serves /v1/secret
+func apiGetSecret(r *http.Request) (*models.SecretValue, error) {
+	q := r.URL.Query()
+	ref := models.SecretRef{Provider: q.Get("provider"), Instance: q.Get("instance"), Vault: q.Get("vault"), Secret: q.Get("name")}
+	field := q.Get("field")
+
+	switch {
+	case q.Get("alias") != "":
+		alias, err := appConfig.GetAlias(q.Get("alias"))
+		if err != nil {
+			return nil, err
+		}
+		ref = models.SecretRef{Provider: alias.Provider, Instance: alias.Instance, Vault: alias.Vault, Secret: alias.Secret}
+		if field == "" {
+			field = alias.Field
+		}
+	case q.Get("ref") != "":
+		parsed, err := models.ParseSecretRef(q.Get("ref"))
+		if err != nil {
+			return nil, newUsageError("%v", err)
+		}
+		ref = parsed
+	}
+
+	if ref.Provider == "" || ref.Vault == "" || ref.Secret == "" {
+		return nil, newUsageError("either alias, ref, or provider, vault and name are required")
+	}
+
+	p, cfg, err := apiOpener("get-secret")(ref.Provider, ref.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := readSecret(r.Context(), p, ref.Vault, ref.Secret, q.Get("version"))
+
+	// Log the read (never the value) before anything is disclosed
+	ref.Instance = cfg.Instance
+	if logErr := recordAccess("serve get-secret", ref, field, err); logErr != nil {
+		return nil, logErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if field != "" {
+		value, ok := secret.Fields[field]
+		if !ok {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("secret '%s' has no field '%s'", ref.Secret, field))
+		}
+		// Return only the selected field, never the others
+		secret.Value = value
+		secret.Fields = nil
+	}
+
+	display, err := output.TransformValue(secret, output.ValueOptions{
+		Mask:     q.Get("mask"),
+		Encoding: q.Get("encoding"),
+	})
+	if err != nil {
+		return nil, newUsageError("%v", err)
+	}
+	return display, nil
+}
+
+// apiSearch serves /v1/search: secrets whose name contains q (case-insensitive)
+// across every matching instance
+func apiSearch(r *http.Request) ([]*models.Secret, error) {
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("q"))
+	if query == "" {
+		return nil, newUsageError("q is required")
+	}
+
+	instances := selectInstances(q.Get("provider"), q.Get("instance"))
+	secrets, err := listSecretsOf(r.Context(), instances, apiOpener("search"), q.Get("vault"))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*models.Secret
+	for _, s := range secrets {
+		if strings.Contains(strings.ToLower(s.Name), query) {
+			matches = append(matches, s)
+		}
+	}
+	output.SortSecrets(matches, output.SortName)
+	if matches == nil {
+		matches = []*models.Secret{}
+	}
+	return matches, nil
+}
+
+// writeFormatted writes the output of a JSON formatter
+func writeFormatted(w http.ResponseWriter, format func() (string, error)) {
+	body, err := format()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintln(w, body)
+}
+
+// writeError writes err with the HTTP status matching its kind
+func writeError(w http.ResponseWriter, err error) {
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		writeAPIError(w, http.StatusBadRequest, "usage", err)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch provider.KindOf(err) {
+	case provider.ErrNotFound:
+		status = http.StatusNotFound
+	case provider.ErrPermissionDenied:
+		status = http.StatusForbidden
+	case provider.ErrAuthExpired:
+		status = http.StatusBadGateway
+	case provider.ErrSealed, provider.ErrUnavailable:
+		status = http.StatusServiceUnavailable
+	}
+	writeAPIError(w, status, provider.KindName(err), err)
+}
+
+// writeAPIError writes a JSON error body: {"error": "...", "kind": "..."}
+func writeAPIError(w http.ResponseWriter, status int, kind string, err error) {
+	body := map[string]string{"error": err.Error()}
+	if kind != "" {
+		body["kind"] = kind
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
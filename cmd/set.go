@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// setSecretCmd returns the set-secret command
+func setSecretCmd() *cobra.Command {
+	var (
+		value       string
+		fromFile    string
+		fields      []string
+		tags        []string
+		contentType string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set-secret [alias]",
+		Short: "Create or update a secret",
+		Long: `Create or update a secret by alias, by --ref, or by --provider, --vault and
+--name, on providers that support writes (file, kubernetes, memory).
+
+The value comes from --value, --from-file (- for stdin) or, when neither is
+given, stdin with one trailing newline removed. Prefer stdin or a file over
+--value, which is visible in the process list and shell history.
+Multi-field secrets are written with --field key=value (repeatable).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Load config
+			if err := loadConfig(); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			// An alias or reference overrides the individual location flags
+			if len(args) == 1 {
+				alias, err := appConfig.GetAlias(args[0])
+				if err != nil {
+					return err
+				}
+				providerName, instanceName, vaultName, secretName = alias.Provider, alias.Instance, alias.Vault, alias.Secret
+			} else if secretRef != "" {
+				ref, err := models.ParseSecretRef(secretRef)
+				if err != nil {
+					return err
+				}
+				providerName, instanceName, vaultName, secretName = ref.Provider, ref.Instance, ref.Vault, ref.Secret
+			}
+
+			if providerName == "" || vaultName == "" || secretName == "" {
+				return newUsageError("either an alias, --ref, or --provider, --vault and --name are required")
+			}
+
+			secret := &models.SecretValue{Name: secretName, VaultName: vaultName, ContentType: contentType}
+			var err error
+			if secret.Fields, err = parsePairs("--field", fields); err != nil {
+				return err
+			}
+			if secret.Tags, err = parsePairs("--tag", tags); err != nil {
+				return err
+			}
+
+			switch {
+			case cmd.Flags().Changed("value") && fromFile != "":
+				return newUsageError("--value and --from-file cannot be combined")
+			case cmd.Flags().Changed("value"):
+				secret.Value = value
+			case fromFile != "":
+				if secret.Value, err = readValueFile(fromFile); err != nil {
+					return err
+				}
+			case len(secret.Fields) == 0:
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read value from stdin: %w", err)
+				}
+				secret.Value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+			}
+
+			// Get provider, guarded by the configured policies
+			p, cfg, err := openProvider(providerName, instanceName)
+			if err != nil {
+				return err
+			}
+
+			writer, ok := p.(provider.Writer)
+			if !ok || !p.SupportsFeature(provider.FeatureWrite) {
+				return newUsageError("provider %s does not support writing secrets", p.Name())
+			}
+
+			written, err := writer.SetSecret(context.Background(), vaultName, secretName, secret)
+
+			// Log the write (never the value)
+			ref := models.SecretRef{
+				Provider: providerName,
+				Instance: cfg.Instance,
+				Vault:    vaultName,
+				Secret:   secretName,
+			}
+			if logErr := recordAccess(commandName, ref, "", err); logErr != nil {
+				return logErr
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Secret '%s' saved to %s\n", written.Name, ref)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (azure, hashicorp, kubernetes, memory, file, pass, secretsmanager, ssm, gcp, onepassword, bitwarden or a plugin)")
+	cmd.Flags().StringVarP(&instanceName, "instance", "i", "", "Instance name (optional, uses default if not specified)")
+	cmd.Flags().StringVarP(&vaultName, "vault", "v", "", "Vault name")
+	cmd.Flags().StringVarP(&secretName, "name", "n", "", "Secret name")
+	cmd.Flags().StringVarP(&secretRef, "ref", "r", "", "Secret reference as provider/instance/vault/secret (replaces --provider, --instance, --vault and --name)")
+	cmd.Flags().StringVar(&value, "value", "", "Secret value (visible to other local users; prefer stdin)")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the value from a file (- for stdin), kept byte for byte")
+	cmd.Flags().StringArrayVar(&fields, "field", nil, "Field of a multi-field secret as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag as key=value (repeatable)")
+	cmd.Flags().StringVar(&contentType, "content-type", "", "Content type of the value (e.g. application/json)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Config file path (optional)")
+	return cmd
+}
+
+// readValueFile reads a secret value from a file, or from stdin for "-"
+func readValueFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	return string(data), nil
+}
+
+// parsePairs parses repeated key=value flags
+func parsePairs(flag string, pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, newUsageError("invalid %s '%s' (expected key=value)", flag, pair)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/ylchen07/smart-keyvault/internal/output"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// streamListing reports whether a listing should be streamed instead of
+// buffered: NDJSON output without --sort, since sorting needs every item
+func streamListing() bool {
+	return output.Format(formatType) == output.FormatNDJSON && sortKey == ""
+}
+
+// streamVaults writes vaults as NDJSON while providers page through them
+func streamVaults(ctx context.Context) error {
+	w := output.NewNDJSONWriter(os.Stdout)
+	emit := func(v *models.Vault) error { return w.Write(v) }
+
+	if queryAll {
+		return streamAllVaults(ctx, emit)
+	}
+
+	p, err := resolveProvider()
+	if err != nil {
+		return err
+	}
+
+	for v, err := range provider.StreamVaults(ctx, p) {
+		if err != nil {
+			return err
+		}
+		if err := emit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamSecrets writes secrets as NDJSON while providers page through them
+func streamSecrets(ctx context.Context) error {
+	w := output.NewNDJSONWriter(os.Stdout)
+	emit := func(s *models.Secret) error { return w.Write(s) }
+
+	if queryAll {
+		return streamAllSecrets(ctx, vaultName, emit)
+	}
+
+	p, err := resolveProvider()
+	if err != nil {
+		return err
+	}
+
+	for s, err := range provider.StreamSecrets(ctx, p, vaultName) {
+		if err != nil {
+			return err
+		}
+		if !showSecret(s) {
+			continue
+		}
+		if err := emit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveProvider returns the provider selected by --provider and --instance
+func resolveProvider() (provider.Provider, error) {
+	p, _, err := openProvider(providerName, instanceName)
+	return p, err
+}
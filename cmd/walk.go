@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/output"
+	"github.com/ylchen07/smart-keyvault/internal/policy"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// walkFunc is called for every secret visited by walkSecrets
+// value is nil for disabled secrets, whose values cannot be read.
+type walkFunc func(vault *models.Vault, secret *models.Secret, value *models.SecretValue) error
+
+// walkSecrets visits every secret in vault (or in every vault of p) and
+// fetches its value, recording each read in the audit log. Vaults are walked
+// in name order and secrets in --sort order so repeated walks diff cleanly.
+//
+// All vaults are listed before any value is read, so policy limits on the
+// walk size refuse the walk up front. Vaults or secrets that cannot be read
+// are reported on stderr and skipped; policy violations and errors from fn
+// stop the walk.
+func walkSecrets(ctx context.Context, inst config.InstanceRef, p provider.Provider, vault string, fn walkFunc) error {
+	// Determine which vaults to process
+	var vaults []*models.Vault
+	if vault != "" {
+		// Single vault specified
+		vaults = []*models.Vault{{Name: vault}}
+	} else {
+		// Get all vaults
+		allVaults, err := p.ListVaults(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list vaults: %w", err)
+		}
+		vaults = allVaults
+	}
+
+	output.SortVaults(vaults, output.SortName)
+
+	// List secrets in every vault first
+	secretsByVault := make(map[string][]*models.Secret, len(vaults))
+	for _, v := range vaults {
+		secrets, err := p.ListSecrets(ctx, v.Name)
+		if err != nil {
+			if isPolicyViolation(err) {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: failed to list secrets in vault %s: %v\n", v.Name, err)
+			continue
+		}
+		output.SortSecrets(secrets, sortKey)
+		secretsByVault[v.Name] = secrets
+	}
+
+	for _, v := range vaults {
+		for _, secret := range secretsByVault[v.Name] {
+			if !secret.Enabled {
+				if err := fn(v, secret, nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Get value for each secret
+			value, err := p.GetSecret(ctx, v.Name, secret.Name)
+			ref := models.SecretRef{Provider: inst.Provider, Instance: inst.Name, Vault: v.Name, Secret: secret.Name}
+			if logErr := recordAccess(commandName, ref, "", err); logErr != nil {
+				return logErr
+			}
+			if err != nil {
+				if isPolicyViolation(err) {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Warning: failed to get secret %s in vault %s: %v\n", secret.Name, v.Name, err)
+				continue
+			}
+
+			if err := fn(v, secret, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isPolicyViolation reports whether err was raised by a policy
+func isPolicyViolation(err error) bool {
+	var violation *policy.Violation
+	return errors.As(err, &violation)
+}
@@ -0,0 +1,84 @@
+// Command smart-keyvault-provider-env is an example provider plugin serving
+// environment variables: one vault, "env", holding every variable that
+// starts with the instance's prefix
+//
+// Build it onto PATH or into ~/.config/smart-keyvault/plugins and configure:
+//
+//	providers:
+//	  env:
+//	    enabled: true
+//	    instances:
+//	      - name: app
+//	        prefix: APP_
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+	"github.com/ylchen07/smart-keyvault/pkg/plugin"
+)
+
+// vaultName is the only vault
+const vaultName = "env"
+
+// envProvider serves the environment variables with a prefix
+type envProvider struct {
+	prefix string
+}
+
+// newProvider creates the provider from the instance's settings
+func newProvider(cfg *plugin.Config) (plugin.Provider, error) {
+	prefix, _ := cfg.Settings["prefix"].(string)
+	if prefix == "" {
+		return nil, fmt.Errorf("prefix is required for the env provider")
+	}
+	return &envProvider{prefix: prefix}, nil
+}
+
+func (p *envProvider) Name() string {
+	return "env"
+}
+
+func (p *envProvider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	return []*models.Vault{{Name: vaultName, Provider: "env"}}, nil
+}
+
+func (p *envProvider) ListSecrets(ctx context.Context, vault string) ([]*models.Secret, error) {
+	if vault != vaultName {
+		return nil, plugin.NewError(plugin.ErrNotFound, fmt.Errorf("vault '%s' not found", vault))
+	}
+
+	var secrets []*models.Secret
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(key, p.prefix); ok && name != "" {
+			secrets = append(secrets, &models.Secret{Name: name, VaultName: vault, Provider: "env", Enabled: true})
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+func (p *envProvider) GetSecret(ctx context.Context, vault, secret string) (*models.SecretValue, error) {
+	value, ok := os.LookupEnv(p.prefix + secret)
+	if vault != vaultName || !ok {
+		return nil, plugin.NewError(plugin.ErrNotFound, fmt.Errorf("secret '%s' not found in vault '%s'", secret, vault))
+	}
+	return &models.SecretValue{Name: secret, Value: value, VaultName: vault, Provider: "env"}, nil
+}
+
+func (p *envProvider) SupportsFeature(feature plugin.Feature) bool {
+	return false
+}
+
+func main() {
+	if err := plugin.Serve(newProvider); err != nil {
+		log.Fatal(err)
+	}
+}
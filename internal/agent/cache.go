@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"container/list"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// valueCache keeps secret values in locked memory for a short time
+// Values are stored JSON-encoded in a lockedBuffer and zeroised when they
+// expire, are evicted or the cache is flushed.
+type valueCache struct {
+	opts config.ValueCache
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Values are *cacheEntry
+	lru     *list.List               // Most recently used at the front
+}
+
+// cacheEntry is one cached secret value
+type cacheEntry struct {
+	key     string
+	buf     *lockedBuffer
+	expires time.Time
+	timer   *time.Timer
+}
+
+// CacheStatus describes the agent's value cache
+type CacheStatus struct {
+	Enabled    bool `json:"enabled"`
+	Entries    int  `json:"entries"`
+	MaxEntries int  `json:"maxEntries"`
+	Locked     bool `json:"locked"` // Every entry is locked into RAM
+}
+
+// newValueCache returns a cache, or nil when value caching is disabled
+func newValueCache(opts config.ValueCache) *valueCache {
+	if !opts.Enabled || opts.MaxEntries <= 0 {
+		return nil
+	}
+	return &valueCache{
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// cacheKey identifies a secret value
+func cacheKey(providerName, instance, vault, secret string) string {
+	return strings.Join([]string{providerName, instance, vault, secret}, "\x00")
+}
+
+// ttl returns how long a value may be cached (0 means never)
+func (c *valueCache) ttl(providerName, instance, vault, secret string) time.Duration {
+	for _, rule := range c.opts.Rules {
+		if !matchPattern(rule.Provider, providerName) || !matchPattern(rule.Instance, instance) ||
+			!matchPattern(rule.Vault, vault) || !matchPattern(rule.Secret, secret) {
+			continue
+		}
+		if rule.Never {
+			return 0
+		}
+		if rule.TTL > 0 {
+			return rule.TTL
+		}
+		break
+	}
+	return c.opts.TTL
+}
+
+// get returns a cached value, or nil if there is none
+func (c *valueCache) get(key string) *models.SecretValue {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(elem)
+		return nil
+	}
+
+	var value models.SecretValue
+	if err := json.Unmarshal(entry.buf.bytes(), &value); err != nil {
+		c.remove(elem)
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return &value
+}
+
+// put caches a value for ttl, evicting the least recently used entries
+// beyond the maximum
+func (c *valueCache) put(key string, value *models.SecretValue, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	buf := newLockedBuffer(data)
+	clear(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	entry := &cacheEntry{key: key, buf: buf, expires: time.Now().Add(ttl)}
+	elem := c.lru.PushFront(entry)
+	c.entries[key] = elem
+
+	// Expired values are wiped right away rather than on the next lookup
+	entry.timer = time.AfterFunc(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.entries[key] == elem {
+			c.remove(elem)
+		}
+	})
+
+	for c.lru.Len() > c.opts.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// forget zeroises and drops a secret's cached value under any instance name,
+// since the default instance is cached under both "" and its own name
+func (c *valueCache) forget(providerName, vault, secret string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		parts := strings.Split(key, "\x00")
+		if parts[0] == providerName && parts[2] == vault && parts[3] == secret {
+			c.remove(elem)
+		}
+	}
+}
+
+// flush zeroises and drops every cached value, returning how many there were
+func (c *valueCache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.lru.Len()
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+	return n
+}
+
+// status reports the cache state
+func (c *valueCache) status() *CacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locked := true
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		if !elem.Value.(*cacheEntry).buf.locked {
+			locked = false
+			break
+		}
+	}
+	return &CacheStatus{Enabled: true, Entries: c.lru.Len(), MaxEntries: c.opts.MaxEntries, Locked: locked}
+}
+
+// remove drops an entry and zeroises its value; the caller holds c.mu
+func (c *valueCache) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	entry.buf.destroy()
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+}
+
+// matchPattern matches name against a glob pattern; empty or "*" matches anything
+func matchPattern(pattern, name string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// dialTimeout bounds connecting to the agent, so a dead socket never slows
+// down the CLI noticeably
+const dialTimeout = 200 * time.Millisecond
+
+// DefaultSocketPath returns the agent socket location
+// Prefers $XDG_RUNTIME_DIR (a per-user tmpfs), falling back to the state dir.
+func DefaultSocketPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "smart-keyvault", "agent.sock"), nil
+	}
+
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "agent.sock"), nil
+}
+
+// Client talks to a running agent
+type Client struct {
+	path string
+}
+
+// Connect returns a client if an agent answers on path
+func Connect(path string) (*Client, error) {
+	c := &Client{path: path}
+	if _, err := c.Call(context.Background(), &Request{Op: OpPing}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Call sends one request and waits for its response
+// Errors returned by the agent keep their provider error kind.
+func (c *Client) Call(ctx context.Context, req *Request) (*Response, error) {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to agent: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send agent request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent response: %w", err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid agent response: %w", err)
+	}
+	if err := resp.err(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RemoteProvider implements provider.Provider by forwarding to the agent,
+// which holds the real provider instance
+type RemoteProvider struct {
+	client   *Client
+	name     string
+	instance string
+}
+
+// NewRemoteProvider returns a provider for an instance served by the agent
+func NewRemoteProvider(client *Client, name, instance string) *RemoteProvider {
+	return &RemoteProvider{client: client, name: name, instance: instance}
+}
+
+// Name returns the provider name
+func (p *RemoteProvider) Name() string {
+	return p.name
+}
+
+// ListVaults lists vaults through the agent
+func (p *RemoteProvider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	resp, err := p.call(ctx, &Request{Op: OpListVaults})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Vaults, nil
+}
+
+// ListSecrets lists secrets through the agent
+func (p *RemoteProvider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	resp, err := p.call(ctx, &Request{Op: OpListSecrets, Vault: vaultName})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Secrets, nil
+}
+
+// GetSecret reads a secret through the agent
+func (p *RemoteProvider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	resp, err := p.call(ctx, &Request{Op: OpGetSecret, Vault: vaultName, Secret: secretName})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("agent returned no secret")
+	}
+	return resp.Value, nil
+}
+
+// GetSecretVersion reads a secret version through the agent
+func (p *RemoteProvider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	resp, err := p.call(ctx, &Request{Op: OpGetVersion, Vault: vaultName, Secret: secretName, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("agent returned no secret")
+	}
+	return resp.Value, nil
+}
+
+// GetSecretMetadata reads secret metadata through the agent
+func (p *RemoteProvider) GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error) {
+	resp, err := p.call(ctx, &Request{Op: OpSecretMetadata, Vault: vaultName, Secret: secretName})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Secret == nil {
+		return nil, fmt.Errorf("agent returned no secret")
+	}
+	return resp.Secret, nil
+}
+
+// SetSecret writes a secret through the agent
+func (p *RemoteProvider) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	resp, err := p.call(ctx, &Request{Op: OpSetSecret, Vault: vaultName, Secret: secretName, Value: secret})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Secret == nil {
+		return nil, fmt.Errorf("agent returned no secret")
+	}
+	return resp.Secret, nil
+}
+
+// SupportsFeature asks the agent about the provider's features
+func (p *RemoteProvider) SupportsFeature(feature provider.Feature) bool {
+	resp, err := p.call(context.Background(), &Request{Op: OpSupports, Feature: int(feature)})
+	return err == nil && resp.Supported
+}
+
+// call sends a request for this provider instance
+func (p *RemoteProvider) call(ctx context.Context, req *Request) (*Response, error) {
+	req.Provider = p.name
+	req.Instance = p.instance
+	return p.client.Call(ctx, req)
+}
@@ -0,0 +1,28 @@
+//go:build !unix
+
+package agent
+
+// lockedBuffer holds bytes on the Go heap; memory locking is not available on
+// this platform, but values are still zeroised when destroyed
+type lockedBuffer struct {
+	data   []byte
+	locked bool // always false here
+}
+
+// newLockedBuffer copies b into a fresh buffer
+func newLockedBuffer(b []byte) *lockedBuffer {
+	buf := &lockedBuffer{data: make([]byte, len(b))}
+	copy(buf.data, b)
+	return buf
+}
+
+// bytes returns the buffer contents; they are only valid until destroy
+func (b *lockedBuffer) bytes() []byte {
+	return b.data
+}
+
+// destroy zeroises the buffer
+func (b *lockedBuffer) destroy() {
+	clear(b.data)
+	b.data = nil
+}
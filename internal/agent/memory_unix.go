@@ -0,0 +1,47 @@
+//go:build unix
+
+package agent
+
+import "golang.org/x/sys/unix"
+
+// lockedBuffer holds bytes in memory kept out of swap where the OS allows it
+type lockedBuffer struct {
+	data   []byte
+	mapped bool // data was mmapped and must be unmapped
+	locked bool // data is mlocked
+}
+
+// newLockedBuffer copies b into a fresh buffer outside the Go heap and locks
+// it into RAM. If mlock fails (e.g. RLIMIT_MEMLOCK is exhausted) the buffer
+// is still used but reports locked as false.
+func newLockedBuffer(b []byte) *lockedBuffer {
+	data, err := unix.Mmap(-1, 0, max(len(b), 1), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		buf := &lockedBuffer{data: make([]byte, len(b))}
+		copy(buf.data, b)
+		return buf
+	}
+
+	buf := &lockedBuffer{data: data[:len(b)], mapped: true}
+	buf.locked = unix.Mlock(data) == nil
+	copy(buf.data, b)
+	return buf
+}
+
+// bytes returns the buffer contents; they are only valid until destroy
+func (b *lockedBuffer) bytes() []byte {
+	return b.data
+}
+
+// destroy zeroises the buffer and releases its memory
+func (b *lockedBuffer) destroy() {
+	clear(b.data[:cap(b.data)])
+	if b.mapped {
+		data := b.data[:cap(b.data)]
+		if b.locked {
+			unix.Munlock(data)
+		}
+		unix.Munmap(data)
+	}
+	b.data = nil
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"errors"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Operations understood by the agent
+const (
+	OpPing           = "ping"
+	OpStatus         = "status"
+	OpListVaults     = "list-vaults"
+	OpListSecrets    = "list-secrets"
+	OpGetSecret      = "get-secret"
+	OpGetVersion     = "get-secret-version"
+	OpSecretMetadata = "secret-metadata"
+	OpSetSecret      = "set-secret"
+	OpSupports       = "supports"
+	OpLock           = "lock"
+	OpUnlock         = "unlock"
+	OpStop           = "stop"
+	OpCacheFlush     = "cache-flush"
+)
+
+// Request is one line sent to the agent
+type Request struct {
+	Op       string              `json:"op"`
+	Provider string              `json:"provider,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Vault    string              `json:"vault,omitempty"`
+	Secret   string              `json:"secret,omitempty"`
+	Version  string              `json:"version,omitempty"`
+	Value    *models.SecretValue `json:"value,omitempty"` // New value for set-secret
+	Feature  int                 `json:"feature,omitempty"`
+}
+
+// Response is one line returned by the agent
+// Error kinds are sent by name so the CLI keeps its exit codes.
+type Response struct {
+	Error     string              `json:"error,omitempty"`
+	ErrorKind string              `json:"errorKind,omitempty"`
+	Vaults    []*models.Vault     `json:"vaults,omitempty"`
+	Secrets   []*models.Secret    `json:"secrets,omitempty"`
+	Secret    *models.Secret      `json:"secret,omitempty"`
+	Value     *models.SecretValue `json:"value,omitempty"`
+	Status    *Status             `json:"status,omitempty"`
+	Supported bool                `json:"supported,omitempty"`
+	Flushed   int                 `json:"flushed,omitempty"` // Values dropped by cache-flush
+}
+
+// Status describes a running agent
+type Status struct {
+	PID       int          `json:"pid"`
+	Locked    bool         `json:"locked"`
+	Uptime    string       `json:"uptime"`
+	IdleFor   string       `json:"idleFor"`
+	Instances []string     `json:"instances"` // Warm provider instances as provider/instance
+	Cache     *CacheStatus `json:"cache"`
+}
+
+// errorResponse encodes err for the wire
+func errorResponse(err error) *Response {
+	return &Response{Error: err.Error(), ErrorKind: provider.KindName(err)}
+}
+
+// err decodes the response error, restoring its kind
+func (r *Response) err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return provider.NewError(provider.KindByName(r.ErrorKind), errors.New(r.Error))
+}
@@ -0,0 +1,508 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxRequestSize bounds a single request line
+const maxRequestSize = 64 * 1024
+
+// ErrLocked is returned for requests to a locked agent
+var ErrLocked = provider.NewError(provider.ErrPermissionDenied, errors.New("agent is locked (run 'smart-keyvault agent unlock')"))
+
+// Factory creates the provider for an instance ("" selects the default)
+// It returns the provider and the resolved instance name.
+type Factory func(providerName, instance string) (provider.Provider, string, error)
+
+// Options configures the agent server
+type Options struct {
+	SocketPath  string        // Unix socket to listen on
+	IdleTimeout time.Duration // Exit after this long without requests (0 disables)
+	MetadataTTL time.Duration // How long vault and secret listings are cached (0 disables)
+	Cache       config.ValueCache
+}
+
+// Server keeps provider instances, credentials and listings warm for CLI
+// invocations connecting over a user-only Unix socket
+type Server struct {
+	opts     Options
+	factory  Factory
+	listener net.Listener
+	started  time.Time
+	creating singleflight.Group // Providers being created, keyed by provider/instance
+
+	mu         sync.Mutex
+	locked     bool
+	providers  map[string]provider.Provider // Keyed by provider/instance
+	listings   map[string]*listing          // Keyed by operation and location
+	values     *valueCache                  // Nil unless value caching is enabled
+	lastActive time.Time
+	idle       *time.Timer
+	closed     bool
+}
+
+// listing is a cached vault or secret listing
+type listing struct {
+	fetched time.Time
+	vaults  []*models.Vault
+	secrets []*models.Secret
+}
+
+// NewServer creates an agent server; call Serve to start it
+func NewServer(opts Options, factory Factory) *Server {
+	return &Server{
+		opts:      opts,
+		factory:   factory,
+		providers: make(map[string]provider.Provider),
+		listings:  make(map[string]*listing),
+		values:    newValueCache(opts.Cache),
+	}
+}
+
+// Serve listens on the socket and handles requests until the agent is
+// stopped or idles out
+func (s *Server) Serve() error {
+	l, err := listen(s.opts.SocketPath)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listener = l
+	s.started = time.Now()
+	s.lastActive = s.started
+	if s.opts.IdleTimeout > 0 {
+		s.idle = time.AfterFunc(s.opts.IdleTimeout, s.Close)
+	}
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops the server, closes its providers and removes the socket
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.values != nil {
+		s.values.flush()
+	}
+	removeSocket(s.opts.SocketPath)
+	providers := s.providers
+	s.providers = make(map[string]provider.Provider)
+	s.mu.Unlock()
+
+	closeProviders(providers)
+}
+
+// closeProviders closes each distinct provider that holds resources (e.g.
+// plugin processes), releasing the credentials they were given
+// Providers are cached under more than one key, so each is closed once.
+func closeProviders(providers map[string]provider.Provider) {
+	closed := make(map[provider.Provider]bool)
+	for _, p := range providers {
+		if closed[p] {
+			continue
+		}
+		closed[p] = true
+		if closer, ok := p.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// listen creates the socket with user-only permissions, replacing a stale
+// socket left by an agent that did not shut down cleanly
+func listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create agent directory: %w", err)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("refusing to replace %s: not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("agent is already running on %s", path)
+		}
+		removeSocket(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict agent socket: %w", err)
+	}
+	return l, nil
+}
+
+// removeSocket removes path if it is a socket, leaving any other file alone
+func removeSocket(path string) {
+	if info, err := os.Lstat(path); err == nil && info.Mode().Type() == os.ModeSocket {
+		os.Remove(path)
+	}
+}
+
+// handle serves line-delimited JSON requests on one connection
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxRequestSize)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req Request
+		var resp *Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = errorResponse(fmt.Errorf("invalid request: %w", err))
+		} else {
+			resp = s.dispatch(&req)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		if req.Op == OpStop {
+			s.Close()
+			return
+		}
+	}
+}
+
+// dispatch runs one request
+func (s *Server) dispatch(req *Request) *Response {
+	// Probes from idle CLI checks must not keep the agent alive
+	if req.Op != OpPing && req.Op != OpStatus {
+		s.touch()
+	}
+
+	switch req.Op {
+	case OpPing, OpStop:
+		return &Response{}
+	case OpStatus:
+		return &Response{Status: s.status()}
+	case OpLock:
+		s.lock()
+		return &Response{}
+	case OpUnlock:
+		s.mu.Lock()
+		s.locked = false
+		s.mu.Unlock()
+		return &Response{}
+	case OpCacheFlush:
+		if s.values == nil {
+			return &Response{}
+		}
+		return &Response{Flushed: s.values.flush()}
+	}
+
+	p, err := s.provider(req.Provider, req.Instance)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	ctx := context.Background()
+	switch req.Op {
+	case OpSupports:
+		return &Response{Supported: p.SupportsFeature(provider.Feature(req.Feature))}
+
+	case OpListVaults:
+		vaults, err := s.cachedVaults(ctx, p, req)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Vaults: vaults}
+
+	case OpListSecrets:
+		secrets, err := s.cachedSecrets(ctx, p, req)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secrets: secrets}
+
+	case OpGetSecret:
+		value, err := s.cachedValue(ctx, p, req)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Value: value}
+
+	case OpGetVersion:
+		// Specific versions bypass the value cache
+		versioner, ok := p.(provider.Versioner)
+		if !ok {
+			return errorResponse(fmt.Errorf("provider %s does not support reading secret versions", p.Name()))
+		}
+		value, err := versioner.GetSecretVersion(ctx, req.Vault, req.Secret, req.Version)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Value: value}
+
+	case OpSecretMetadata:
+		reader, ok := p.(provider.MetadataReader)
+		if !ok {
+			return &Response{Secret: &models.Secret{Name: req.Secret, VaultName: req.Vault, Provider: p.Name(), Enabled: true}}
+		}
+		secret, err := reader.GetSecretMetadata(ctx, req.Vault, req.Secret)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secret: secret}
+
+	case OpSetSecret:
+		writer, ok := p.(provider.Writer)
+		if !ok {
+			return errorResponse(fmt.Errorf("provider %s does not support writing secrets", p.Name()))
+		}
+		if req.Value == nil {
+			return errorResponse(fmt.Errorf("set-secret requires a value"))
+		}
+		secret, err := writer.SetSecret(ctx, req.Vault, req.Secret, req.Value)
+		if err != nil {
+			return errorResponse(err)
+		}
+		s.invalidate(req)
+		return &Response{Secret: secret}
+
+	default:
+		return errorResponse(fmt.Errorf("unknown operation: %s", req.Op))
+	}
+}
+
+// provider returns the warm provider for an instance, creating it on first use
+// Providers are created outside the lock, so a slow login on one instance
+// does not hold up requests for others; concurrent requests for the same
+// instance share a single creation.
+func (s *Server) provider(name, instance string) (provider.Provider, error) {
+	key := name + "/" + instance
+
+	s.mu.Lock()
+	if s.locked {
+		s.mu.Unlock()
+		return nil, ErrLocked
+	}
+	if p, ok := s.providers[key]; ok {
+		s.mu.Unlock()
+		return p, nil
+	}
+	s.mu.Unlock()
+
+	v, err, _ := s.creating.Do(key, func() (interface{}, error) {
+		p, resolved, err := s.factory(name, instance)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// The agent may have been locked or stopped while the provider was created
+		if s.locked || s.closed {
+			if closer, ok := p.(io.Closer); ok {
+				closer.Close()
+			}
+			return nil, ErrLocked
+		}
+
+		// Cache under the resolved name too, so default-instance requests share it
+		s.providers[key] = p
+		s.providers[name+"/"+resolved] = p
+		return p, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(provider.Provider), nil
+}
+
+// cachedVaults returns a vault listing, from cache while it is fresh
+func (s *Server) cachedVaults(ctx context.Context, p provider.Provider, req *Request) ([]*models.Vault, error) {
+	key := strings.Join([]string{OpListVaults, req.Provider, req.Instance}, "\x00")
+	if l := s.cached(key); l != nil {
+		return l.vaults, nil
+	}
+
+	vaults, err := p.ListVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.store(key, &listing{vaults: vaults})
+	return vaults, nil
+}
+
+// cachedSecrets returns a secret listing, from cache while it is fresh
+func (s *Server) cachedSecrets(ctx context.Context, p provider.Provider, req *Request) ([]*models.Secret, error) {
+	key := strings.Join([]string{OpListSecrets, req.Provider, req.Instance, req.Vault}, "\x00")
+	if l := s.cached(key); l != nil {
+		return l.secrets, nil
+	}
+
+	secrets, err := p.ListSecrets(ctx, req.Vault)
+	if err != nil {
+		return nil, err
+	}
+	s.store(key, &listing{secrets: secrets})
+	return secrets, nil
+}
+
+// cachedValue reads a secret, from the value cache when it is enabled
+func (s *Server) cachedValue(ctx context.Context, p provider.Provider, req *Request) (*models.SecretValue, error) {
+	if s.values == nil {
+		return p.GetSecret(ctx, req.Vault, req.Secret)
+	}
+
+	key := cacheKey(req.Provider, req.Instance, req.Vault, req.Secret)
+	if value := s.values.get(key); value != nil {
+		return value, nil
+	}
+
+	value, err := p.GetSecret(ctx, req.Vault, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+	s.values.put(key, value, s.values.ttl(req.Provider, req.Instance, req.Vault, req.Secret))
+	return value, nil
+}
+
+// invalidate drops the listings and cached value a write may have changed
+func (s *Server) invalidate(req *Request) {
+	s.mu.Lock()
+	for key := range s.listings {
+		if strings.HasPrefix(key, OpListVaults+"\x00"+req.Provider+"\x00") ||
+			strings.HasPrefix(key, OpListSecrets+"\x00"+req.Provider+"\x00") {
+			delete(s.listings, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.values != nil {
+		s.values.forget(req.Provider, req.Vault, req.Secret)
+	}
+}
+
+// cached returns a listing younger than the metadata TTL
+func (s *Server) cached(key string) *listing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.listings[key]
+	if !ok || time.Since(l.fetched) > s.opts.MetadataTTL {
+		return nil
+	}
+	return l
+}
+
+// store caches a listing when caching is enabled
+func (s *Server) store(key string, l *listing) {
+	if s.opts.MetadataTTL <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l.fetched = time.Now()
+	s.listings[key] = l
+}
+
+// lock closes and drops every provider (and with it cached credentials),
+// listing and cached value
+func (s *Server) lock() {
+	s.mu.Lock()
+	s.locked = true
+	providers := s.providers
+	s.providers = make(map[string]provider.Provider)
+	s.listings = make(map[string]*listing)
+	if s.values != nil {
+		s.values.flush()
+	}
+	s.mu.Unlock()
+
+	// Closing may wait for plugin processes, so it happens outside the lock
+	closeProviders(providers)
+}
+
+// touch records activity and restarts the idle timer
+func (s *Server) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastActive = time.Now()
+	if s.idle != nil {
+		s.idle.Reset(s.opts.IdleTimeout)
+	}
+}
+
+// status reports the agent state
+func (s *Server) status() *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instances := make([]string, 0, len(s.providers))
+	seen := make(map[provider.Provider]bool)
+	for key, p := range s.providers {
+		if !seen[p] && !strings.HasSuffix(key, "/") {
+			seen[p] = true
+			instances = append(instances, key)
+		}
+	}
+	sort.Strings(instances)
+
+	cache := &CacheStatus{}
+	if s.values != nil {
+		cache = s.values.status()
+	}
+
+	return &Status{
+		PID:       os.Getpid(),
+		Locked:    s.locked,
+		Uptime:    time.Since(s.started).Round(time.Second).String(),
+		IdleFor:   time.Since(s.lastActive).Round(time.Second).String(),
+		Instances: instances,
+		Cache:     cache,
+	}
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Status classifies a secret in an expiry report
+type Status string
+
+const (
+	// StatusOK means no threshold is breached
+	StatusOK Status = "ok"
+	// StatusExpired means the expiry date has passed
+	StatusExpired Status = "expired"
+	// StatusExpiring means the secret expires within the warning window
+	StatusExpiring Status = "expiring"
+	// StatusStale means the secret has not been rotated within the maximum age
+	StatusStale Status = "stale"
+)
+
+// Thresholds configures when a secret is reported
+type Thresholds struct {
+	ExpiringWithin time.Duration // Warn about secrets expiring within this window (0 disables)
+	MaxAge         time.Duration // Warn about secrets not updated for this long (0 disables)
+}
+
+// Finding is the expiry status of one secret
+type Finding struct {
+	models.SecretRef `yaml:",inline"`
+	Status           Status     `json:"status" yaml:"status"`
+	ExpiresOn        *time.Time `json:"expiresOn,omitempty" yaml:"expiresOn,omitempty"`
+	UpdatedOn        *time.Time `json:"updatedOn,omitempty" yaml:"updatedOn,omitempty"`
+	Message          string     `json:"message" yaml:"message"`
+}
+
+// Breached reports whether the finding breaches a threshold
+func (f Finding) Breached() bool {
+	return f.Status != StatusOK
+}
+
+// CheckExpiry evaluates a secret's timestamps against the thresholds at now
+// Expiry takes precedence over rotation age. Secrets without timestamps are
+// reported as ok since there is nothing to check.
+func CheckExpiry(secret *models.Secret, now time.Time, t Thresholds) Finding {
+	f := Finding{
+		SecretRef: models.SecretRef{
+			Provider: secret.Provider,
+			Instance: secret.Instance,
+			Vault:    secret.VaultName,
+			Secret:   secret.Name,
+		},
+		Status:    StatusOK,
+		ExpiresOn: secret.ExpiresOn,
+		UpdatedOn: secret.UpdatedOn,
+	}
+
+	if secret.ExpiresOn != nil {
+		remaining := secret.ExpiresOn.Sub(now)
+		switch {
+		case remaining <= 0:
+			f.Status = StatusExpired
+			f.Message = fmt.Sprintf("expired %s ago", formatDays(-remaining))
+			return f
+		case t.ExpiringWithin > 0 && remaining <= t.ExpiringWithin:
+			f.Status = StatusExpiring
+			f.Message = fmt.Sprintf("expires in %s", formatDays(remaining))
+			return f
+		}
+	}
+
+	if secret.UpdatedOn != nil && t.MaxAge > 0 {
+		if age := now.Sub(*secret.UpdatedOn); age > t.MaxAge {
+			f.Status = StatusStale
+			f.Message = fmt.Sprintf("not rotated for %s (max %s)", formatDays(age), formatDays(t.MaxAge))
+			return f
+		}
+	}
+
+	switch {
+	case secret.ExpiresOn != nil:
+		f.Message = fmt.Sprintf("expires in %s", formatDays(secret.ExpiresOn.Sub(now)))
+	case secret.UpdatedOn != nil:
+		f.Message = fmt.Sprintf("updated %s ago", formatDays(now.Sub(*secret.UpdatedOn)))
+	default:
+		f.Message = "no expiry or update timestamp"
+	}
+	return f
+}
+
+// SortFindings orders findings by severity, then soonest expiry, then reference
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severity(a.Status) != severity(b.Status) {
+			return severity(a.Status) > severity(b.Status)
+		}
+		if a.ExpiresOn != nil && b.ExpiresOn != nil && !a.ExpiresOn.Equal(*b.ExpiresOn) {
+			return a.ExpiresOn.Before(*b.ExpiresOn)
+		}
+		return a.SecretRef.String() < b.SecretRef.String()
+	})
+}
+
+// severity ranks statuses from most to least urgent
+func severity(s Status) int {
+	switch s {
+	case StatusExpired:
+		return 3
+	case StatusExpiring:
+		return 2
+	case StatusStale:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// formatDays renders a duration in whole days, e.g. "12d"
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
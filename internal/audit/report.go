@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/output"
+)
+
+// Report formats supported by audit commands
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// FormatFindings renders findings as a table, JSON or JUnit XML
+// name identifies the check in JUnit output (e.g. "expiry").
+func FormatFindings(name string, findings []Finding, format string) (string, error) {
+	switch format {
+	case FormatTable:
+		return formatTable(findings), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatJUnit:
+		return formatJUnit(name, findings)
+	default:
+		return "", fmt.Errorf("unsupported report format: %s (use table, json or junit)", format)
+	}
+}
+
+// formatTable renders findings as aligned columns
+func formatTable(findings []Finding) string {
+	rows := [][]string{{"STATUS", "SECRET", "EXPIRES", "UPDATED", "MESSAGE"}}
+	for _, f := range findings {
+		rows = append(rows, []string{
+			string(f.Status),
+			f.SecretRef.String(),
+			formatDate(f.ExpiresOn),
+			formatDate(f.UpdatedOn),
+			f.Message,
+		})
+	}
+	return output.WriteTable(rows)
+}
+
+// formatDate renders an optional timestamp as a date ("-" when unset)
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// JUnit XML schema (the subset understood by common CI systems)
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+}
+
+// formatJUnit renders findings as JUnit XML with one test suite per vault
+// and one test case per secret; breached thresholds are failures.
+func formatJUnit(name string, findings []Finding) (string, error) {
+	suites := make(map[string]*junitTestSuite)
+	var suiteNames []string
+
+	report := junitTestSuites{Name: name}
+	for _, f := range findings {
+		vault := strings.Join([]string{f.Provider, f.Instance, f.Vault}, "/")
+		suite, ok := suites[vault]
+		if !ok {
+			suite = &junitTestSuite{Name: vault}
+			suites[vault] = suite
+			suiteNames = append(suiteNames, vault)
+		}
+
+		tc := junitTestCase{Name: f.Secret, ClassName: name + "." + vault}
+		if f.Breached() {
+			tc.Failure = &junitFailure{Type: string(f.Status), Message: f.Message}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		report.Tests++
+	}
+
+	sort.Strings(suiteNames)
+	for _, vault := range suiteNames {
+		report.Suites = append(report.Suites, *suites[vault])
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
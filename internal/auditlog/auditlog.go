@@ -0,0 +1,285 @@
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// DefaultFileName is the audit log file name inside the state directory
+const DefaultFileName = "audit.log"
+
+// Outcomes of a recorded access
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Entry records one secret access
+// Only the reference is stored, never the secret value. Each entry carries
+// the hash of its predecessor, so edits, insertions and deletions inside the
+// log break the chain.
+type Entry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	models.SecretRef
+	Field    string `json:"field,omitempty"`
+	Outcome  string `json:"outcome"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// NewEntry creates an entry for the current user and host
+func NewEntry(command string, ref models.SecretRef) *Entry {
+	host, _ := os.Hostname()
+	return &Entry{
+		Time:      time.Now().UTC(),
+		User:      currentUser(),
+		Host:      host,
+		Command:   command,
+		SecretRef: ref,
+		Outcome:   OutcomeOK,
+	}
+}
+
+// currentUser returns the login name of the current user
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// computeHash returns the hex SHA-256 of the entry with its Hash cleared
+func (e *Entry) computeHash() (string, error) {
+	c := *e
+	c.Hash = ""
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sink receives a copy of every appended entry (e.g. syslog)
+type Sink interface {
+	Write(e *Entry) error
+	Close() error
+}
+
+// Log is an append-only, hash-chained JSON-lines file
+type Log struct {
+	path  string
+	sinks []Sink
+
+	// mu serializes appends within the process; the file lock only covers
+	// other processes, and only where the platform supports it
+	mu sync.Mutex
+}
+
+// Open returns the log at path; entries are also sent to sinks
+// The file is created on the first Append.
+func Open(path string, sinks ...Sink) *Log {
+	return &Log{path: path, sinks: sinks}
+}
+
+// Close releases the sinks (e.g. the syslog connection)
+func (l *Log) Close() error {
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Path returns the log file location
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains e to the last entry and writes it to the log and every sink
+// Seq, PrevHash and Hash are filled in. The file is locked while appending so
+// concurrent invocations keep the chain intact.
+func (l *Log) Append(e *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer unlockFile(f)
+
+	last, err := lastEntry(f)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		e.Seq = last.Seq + 1
+		e.PrevHash = last.Hash
+	} else {
+		e.Seq = 1
+		e.PrevHash = ""
+	}
+
+	if e.Hash, err = e.computeHash(); err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	var sinkErrs []error
+	for _, sink := range l.sinks {
+		if err := sink.Write(e); err != nil {
+			sinkErrs = append(sinkErrs, err)
+		}
+	}
+	if len(sinkErrs) > 0 {
+		return fmt.Errorf("failed to write audit sink: %w", errors.Join(sinkErrs...))
+	}
+
+	return nil
+}
+
+// lastEntry reads the final line of the log, or returns nil for an empty log
+func lastEntry(f *os.File) (*Entry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	// Read progressively larger chunks from the end until a full line is found
+	for chunk := int64(4096); ; chunk *= 2 {
+		if chunk > size {
+			chunk = size
+		}
+
+		buf := make([]byte, chunk)
+		if _, err := f.ReadAt(buf, size-chunk); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		buf = bytes.TrimRight(buf, "\n")
+		i := bytes.LastIndexByte(buf, '\n')
+		if i < 0 && chunk < size {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(buf[i+1:], &e); err != nil {
+			return nil, fmt.Errorf("failed to parse last audit entry: %w", err)
+		}
+		return &e, nil
+	}
+}
+
+// Read returns every entry in the log at path
+// A missing file yields no entries.
+func Read(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ChainError reports where the hash chain is broken
+type ChainError struct {
+	Seq    int64  // Sequence number of the first bad entry
+	Reason string // What does not match
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log tampered at entry %d: %s", e.Seq, e.Reason)
+}
+
+// Verify checks sequence numbers and hashes of the full log
+// Returns a *ChainError at the first entry that does not match. Truncation of
+// the newest entries cannot be detected locally; use a remote sink for that.
+func Verify(entries []*Entry) error {
+	var prev *Entry
+	for _, e := range entries {
+		switch {
+		case prev == nil && e.Seq != 1:
+			return &ChainError{Seq: e.Seq, Reason: "log does not start at entry 1"}
+		case prev != nil && e.Seq != prev.Seq+1:
+			return &ChainError{Seq: e.Seq, Reason: fmt.Sprintf("expected entry %d", prev.Seq+1)}
+		case prev != nil && e.PrevHash != prev.Hash:
+			return &ChainError{Seq: e.Seq, Reason: "previous hash does not match"}
+		case prev == nil && e.PrevHash != "":
+			return &ChainError{Seq: e.Seq, Reason: "first entry has a previous hash"}
+		}
+
+		hash, err := e.computeHash()
+		if err != nil {
+			return err
+		}
+		if hash != e.Hash {
+			return &ChainError{Seq: e.Seq, Reason: "entry hash does not match its contents"}
+		}
+		prev = e
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix || aix
+
+package auditlog
+
+import "os"
+
+// lockFile is a no-op where advisory locks are unavailable
+func lockFile(f *os.File) error { return nil }
+
+// unlockFile is a no-op where advisory locks are unavailable
+func unlockFile(f *os.File) error { return nil }
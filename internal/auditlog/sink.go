@@ -0,0 +1,46 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// JSONLinesSink appends a copy of every entry to a JSON-lines file, e.g. one
+// collected by a log shipper
+type JSONLinesSink struct {
+	path string
+}
+
+// NewJSONLinesSink creates a sink writing to path
+func NewJSONLinesSink(path string) *JSONLinesSink {
+	return &JSONLinesSink{path: path}
+}
+
+// Write appends e as a single line
+func (s *JSONLinesSink) Write(e *Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", s.path, err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Close does nothing: the file is opened for each entry
+func (s *JSONLinesSink) Close() error {
+	return nil
+}
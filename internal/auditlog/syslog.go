@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogSink sends every entry to the local syslog daemon as JSON
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the local syslog daemon
+func NewSyslogSink() (Sink, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_AUTH, "smart-keyvault")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogSink{writer: w}, nil
+}
+
+// Write sends e as a single syslog message
+func (s *SyslogSink) Write(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.writer.Info(string(data))
+}
+
+// Close closes the connection to the syslog daemon
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package auditlog
+
+import "fmt"
+
+// NewSyslogSink is not available on this platform
+func NewSyslogSink() (Sink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
@@ -0,0 +1,101 @@
+package aws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// updated is the timestamp the fake endpoint reports (epoch seconds on the wire)
+var updated = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// credentialRegion extracts the signing region from an Authorization header
+var credentialRegion = regexp.MustCompile(`Credential=[^/]+/[^/]+/([^/]+)/`)
+
+// handler answers one API operation with a status and a JSON body
+type handler func(req map[string]interface{}) (int, interface{})
+
+// call is a request received by the fake endpoint
+type call struct {
+	operation string // X-Amz-Target, e.g. secretsmanager.ListSecrets
+	region    string
+	input     map[string]interface{}
+}
+
+// fakeEndpoint serves the AWS JSON 1.1 protocol from handlers keyed by
+// operation, standing in for moto or LocalStack behind the endpoint setting
+type fakeEndpoint struct {
+	url string
+
+	mu    sync.Mutex
+	calls []call
+}
+
+// newFakeEndpoint starts a fake endpoint and points the SDK's credential
+// chain at static test credentials only
+func newFakeEndpoint(t *testing.T, handlers map[string]handler) *fakeEndpoint {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_MAX_ATTEMPTS", "1")
+
+	f := &fakeEndpoint{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := r.Header.Get("X-Amz-Target")
+		var input map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("%s: invalid request body: %v", operation, err)
+		}
+
+		region := ""
+		if m := credentialRegion.FindStringSubmatch(r.Header.Get("Authorization")); m != nil {
+			region = m[1]
+		}
+		f.mu.Lock()
+		f.calls = append(f.calls, call{operation: operation, region: region, input: input})
+		f.mu.Unlock()
+
+		status, body := awsError("UnknownOperationException")
+		if h, ok := handlers[operation]; ok {
+			status, body = h(input)
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	f.url = server.URL
+	return f
+}
+
+// received returns the calls made so far
+func (f *fakeEndpoint) received() []call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]call(nil), f.calls...)
+}
+
+// awsError returns an AWS JSON error response of the given type
+func awsError(code string) (int, interface{}) {
+	return http.StatusBadRequest, map[string]string{"__type": code, "message": code + " from fake endpoint"}
+}
+
+// epoch returns t as AWS JSON timestamps encode it
+func epoch(t time.Time) float64 {
+	return float64(t.Unix())
+}
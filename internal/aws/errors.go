@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// classifyError maps AWS SDK errors onto provider error kinds
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ResourceNotFoundException", "ParameterNotFound", "ParameterVersionNotFound":
+			return provider.NewError(provider.ErrNotFound, err)
+		case "AccessDeniedException", "AccessDenied", "DecryptionFailure", "KMSAccessDeniedException":
+			return provider.NewError(provider.ErrPermissionDenied, err)
+		case "ExpiredTokenException", "ExpiredToken", "UnrecognizedClientException",
+			"InvalidClientTokenId", "InvalidSignatureException":
+			return provider.NewError(provider.ErrAuthExpired, err)
+		case "ThrottlingException", "InternalServiceError", "InternalServerError", "ServiceUnavailable":
+			return provider.NewError(provider.ErrUnavailable, err)
+		}
+	}
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch status := respErr.HTTPStatusCode(); {
+		case status == http.StatusNotFound:
+			return provider.NewError(provider.ErrNotFound, err)
+		case status == http.StatusForbidden:
+			return provider.NewError(provider.ErrPermissionDenied, err)
+		case status == http.StatusUnauthorized:
+			return provider.NewError(provider.ErrAuthExpired, err)
+		case status == http.StatusTooManyRequests, status >= 500:
+			return provider.NewError(provider.ErrUnavailable, err)
+		}
+	}
+
+	// The credential chain found nothing usable (e.g. an expired SSO
+	// session); the SDK does not export a type for this case.
+	if strings.Contains(err.Error(), "failed to retrieve credentials") ||
+		strings.Contains(err.Error(), "failed to refresh cached credentials") {
+		return provider.NewError(provider.ErrAuthExpired, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	return err
+}
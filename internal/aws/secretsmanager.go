@@ -0,0 +1,264 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// versionIDPattern matches Secrets Manager version IDs (UUIDs); any other
+// --version is taken as a staging label such as AWSPREVIOUS
+var versionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F-]{27,}$`)
+
+// SecretsManager implements the provider.Provider interface for AWS Secrets
+// Manager: regions are vaults and secrets are secrets
+type SecretsManager struct {
+	cfg      awssdk.Config
+	regions  []string
+	endpoint string
+
+	mu      sync.Mutex
+	clients map[string]*secretsmanager.Client // Keyed by region
+}
+
+// SecretsManagerSchema declares the settings of a Secrets Manager instance
+var SecretsManagerSchema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "profile"},
+		{Name: "regions", Type: provider.StringList},
+		{Name: "role_arn"},
+		{Name: "endpoint"},
+	},
+}
+
+// NewSecretsManagerProvider creates a new AWS Secrets Manager provider
+// Configuration options:
+//   - "profile" (string): shared config profile (optional, defaults to the default chain)
+//   - "regions" ([]string): regions listed as vaults (optional, defaults to the profile's region)
+//   - "role_arn" (string): role to assume (optional)
+//   - "endpoint" (string): custom endpoint, e.g. LocalStack (optional)
+func NewSecretsManagerProvider(cfg *provider.Config) (provider.Provider, error) {
+	s := readSettings(cfg)
+
+	awsCfg, err := loadConfig(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := s.regions
+	if len(regions) == 0 {
+		regions = []string{awsCfg.Region}
+	}
+
+	return &SecretsManager{
+		cfg:      awsCfg,
+		regions:  regions,
+		endpoint: s.endpoint,
+		clients:  make(map[string]*secretsmanager.Client),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *SecretsManager) Name() string {
+	return "secretsmanager"
+}
+
+// ListVaults returns the configured regions
+func (p *SecretsManager) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	vaults := make([]*models.Vault, 0, len(p.regions))
+	for _, region := range p.regions {
+		vaults = append(vaults, &models.Vault{Name: region, Provider: "secretsmanager"})
+	}
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the secrets of a region sorted by name
+func (p *SecretsManager) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	var secrets []*models.Secret
+	for secret, err := range p.StreamSecrets(ctx, vaultName) {
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// StreamSecrets yields the secrets of a region page by page
+func (p *SecretsManager) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		client, err := p.client(vaultName)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to list secrets: %w", err))
+			return
+		}
+
+		pager := secretsmanager.NewListSecretsPaginator(client, &secretsmanager.ListSecretsInput{})
+
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list secrets: %w", classifyError(err)))
+				return
+			}
+
+			for _, entry := range page.SecretList {
+				if entry.Name == nil {
+					continue
+				}
+				if !yield(&models.Secret{
+					Name:      *entry.Name,
+					VaultName: vaultName,
+					Provider:  "secretsmanager",
+					Enabled:   entry.DeletedDate == nil,
+					CreatedOn: entry.CreatedDate,
+					UpdatedOn: entry.LastChangedDate,
+				}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetSecret retrieves the AWSCURRENT version of a secret
+func (p *SecretsManager) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.GetSecretVersion(ctx, vaultName, secretName, "")
+}
+
+// GetSecretVersion retrieves a secret by version ID or staging label
+// ("" is AWSCURRENT)
+func (p *SecretsManager) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	client, err := p.client(vaultName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	input := &secretsmanager.GetSecretValueInput{SecretId: awssdk.String(secretName)}
+	switch {
+	case version == "":
+	case versionIDPattern.MatchString(version):
+		input.VersionId = awssdk.String(version)
+	default:
+		input.VersionStage = awssdk.String(version)
+	}
+
+	out, err := client.GetSecretValue(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", classifyError(err))
+	}
+
+	value := &models.SecretValue{
+		Name:      secretName,
+		VaultName: vaultName,
+		Provider:  "secretsmanager",
+		Version:   awssdk.ToString(out.VersionId),
+		UpdatedOn: out.CreatedDate,
+		Metadata: map[string]string{
+			"arn":    awssdk.ToString(out.ARN),
+			"stages": strings.Join(out.VersionStages, ","),
+		},
+	}
+	if out.SecretString != nil {
+		value.Value = *out.SecretString
+		value.Fields = jsonFields(*out.SecretString)
+	} else {
+		value.Value = string(out.SecretBinary)
+		value.ContentType = "application/octet-stream"
+	}
+
+	// Tags and creation time come from the secret's description; a caller
+	// allowed to read the value but not describe it still gets the value
+	if desc, err := client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: awssdk.String(secretName)}); err == nil {
+		value.CreatedOn = desc.CreatedDate
+		value.Tags = tagMap(desc.Tags)
+	}
+
+	return value, nil
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *SecretsManager) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureVersioning, provider.FeatureTags:
+		return true
+	default:
+		return false
+	}
+}
+
+// client returns the cached client for a configured region
+// Other regions are not vaults of this instance and are reported as not found.
+func (p *SecretsManager) client(region string) (*secretsmanager.Client, error) {
+	if !slices.Contains(p.regions, region) {
+		return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("region '%s' is not configured", region))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.clients[region]; ok {
+		return c, nil
+	}
+
+	c := secretsmanager.NewFromConfig(p.cfg, func(o *secretsmanager.Options) {
+		o.Region = region
+		if p.endpoint != "" {
+			o.BaseEndpoint = awssdk.String(p.endpoint)
+		}
+	})
+	p.clients[region] = c
+	return c, nil
+}
+
+// tagMap converts Secrets Manager tags to a map
+func tagMap(tags []smtypes.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		m[awssdk.ToString(t.Key)] = awssdk.ToString(t.Value)
+	}
+	return m
+}
+
+// jsonFields returns the keys of a JSON object value as fields, or nil when
+// the value is not a JSON object. Non-string values keep their JSON form.
+func jsonFields(value string) map[string]string {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return nil
+	}
+
+	fields := make(map[string]string, len(obj))
+	for key, raw := range obj {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			fields[key] = s
+		} else {
+			fields[key] = string(raw)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,257 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+const versionID = "0b1c2d3e-4f5a-6b7c-8d9e-0a1b2c3d4e5f"
+
+// newTestSecretsManager returns a provider for two regions served by f
+func newTestSecretsManager(t *testing.T, f *fakeEndpoint) *SecretsManager {
+	t.Helper()
+
+	p, err := NewSecretsManagerProvider(&provider.Config{
+		Name:     "secretsmanager",
+		Instance: "test",
+		Settings: map[string]interface{}{
+			"regions":  []string{"us-east-1", "eu-west-1"},
+			"endpoint": f.url,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*SecretsManager)
+}
+
+func TestSecretsManagerListVaults(t *testing.T) {
+	f := newFakeEndpoint(t, nil)
+	p := newTestSecretsManager(t, f)
+
+	vaults, err := p.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vaults) != 2 || vaults[0].Name != "eu-west-1" || vaults[1].Name != "us-east-1" {
+		t.Errorf("vaults = %v, want the configured regions sorted", vaults)
+	}
+	if n := len(f.received()); n != 0 {
+		t.Errorf("listing regions made %d API calls, want none", n)
+	}
+}
+
+func TestSecretsManagerListSecrets(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"secretsmanager.ListSecrets": func(req map[string]interface{}) (int, interface{}) {
+			if req["NextToken"] == nil {
+				return 200, map[string]interface{}{
+					"SecretList": []map[string]interface{}{
+						{"Name": "db", "CreatedDate": epoch(updated), "LastChangedDate": epoch(updated)},
+						{"Name": "retired", "DeletedDate": epoch(updated)},
+					},
+					"NextToken": "page-2",
+				}
+			}
+			return 200, map[string]interface{}{
+				"SecretList": []map[string]interface{}{{"Name": "api-key"}},
+			}
+		},
+	})
+	p := newTestSecretsManager(t, f)
+
+	secrets, err := p.ListSecrets(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "api-key,db,retired" {
+		t.Errorf("secrets = %s, want api-key,db,retired", got)
+	}
+	if db := secrets[1]; !db.Enabled || db.VaultName != "eu-west-1" || db.UpdatedOn == nil || !db.UpdatedOn.Equal(updated) {
+		t.Errorf("db = %+v", db)
+	}
+	if secrets[2].Enabled {
+		t.Error("a secret scheduled for deletion is listed as enabled")
+	}
+
+	calls := f.received()
+	if len(calls) != 2 {
+		t.Fatalf("made %d calls, want 2 pages", len(calls))
+	}
+	for _, c := range calls {
+		if c.region != "eu-west-1" {
+			t.Errorf("request signed for %q, want the vault's region", c.region)
+		}
+	}
+	if calls[1].input["NextToken"] != "page-2" {
+		t.Errorf("second page requested with %v", calls[1].input)
+	}
+}
+
+func TestSecretsManagerGetSecret(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"secretsmanager.GetSecretValue": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{
+				"ARN":           "arn:aws:secretsmanager:us-east-1:123456789012:secret:db",
+				"Name":          "db",
+				"VersionId":     versionID,
+				"VersionStages": []string{"AWSCURRENT"},
+				"SecretString":  `{"username":"app","port":5432}`,
+				"CreatedDate":   epoch(updated),
+			}
+		},
+		"secretsmanager.DescribeSecret": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{
+				"CreatedDate": epoch(updated),
+				"Tags":        []map[string]string{{"Key": "owner", "Value": "platform"}},
+			}
+		},
+	})
+	p := newTestSecretsManager(t, f)
+
+	secret, err := p.GetSecret(context.Background(), "us-east-1", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != `{"username":"app","port":5432}` || secret.Version != versionID {
+		t.Errorf("secret = %+v", secret)
+	}
+	if secret.Fields["username"] != "app" || secret.Fields["port"] != "5432" {
+		t.Errorf("Fields = %v, want the JSON keys", secret.Fields)
+	}
+	if secret.Tags["owner"] != "platform" || secret.CreatedOn == nil || secret.Metadata["stages"] != "AWSCURRENT" {
+		t.Errorf("secret = %+v", secret)
+	}
+}
+
+func TestSecretsManagerGetSecretVersion(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"secretsmanager.GetSecretValue": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{"VersionId": versionID, "SecretString": "old"}
+		},
+		"secretsmanager.DescribeSecret": func(req map[string]interface{}) (int, interface{}) {
+			return awsError("AccessDeniedException")
+		},
+	})
+	p := newTestSecretsManager(t, f)
+
+	for _, version := range []string{"AWSPREVIOUS", versionID} {
+		secret, err := p.GetSecretVersion(context.Background(), "us-east-1", "db", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The value is returned even when the caller may not describe the secret
+		if secret.Value != "old" || secret.Tags != nil {
+			t.Errorf("secret = %+v", secret)
+		}
+	}
+
+	var inputs []map[string]interface{}
+	for _, c := range f.received() {
+		if c.operation == "secretsmanager.GetSecretValue" {
+			inputs = append(inputs, c.input)
+		}
+	}
+	if len(inputs) != 2 || inputs[0]["VersionStage"] != "AWSPREVIOUS" || inputs[1]["VersionId"] != versionID {
+		t.Errorf("GetSecretValue inputs = %v, want a staging label then a version ID", inputs)
+	}
+}
+
+func TestSecretsManagerBinary(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"secretsmanager.GetSecretValue": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{"SecretBinary": base64.StdEncoding.EncodeToString([]byte("\x00key"))}
+		},
+	})
+	p := newTestSecretsManager(t, f)
+
+	secret, err := p.GetSecret(context.Background(), "us-east-1", "cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "\x00key" || secret.ContentType != "application/octet-stream" {
+		t.Errorf("secret = %+v", secret)
+	}
+}
+
+func TestSecretsManagerErrors(t *testing.T) {
+	tests := []struct {
+		code string
+		kind error
+	}{
+		{"ResourceNotFoundException", provider.ErrNotFound},
+		{"AccessDeniedException", provider.ErrPermissionDenied},
+		{"DecryptionFailure", provider.ErrPermissionDenied},
+		{"ExpiredTokenException", provider.ErrAuthExpired},
+		{"UnrecognizedClientException", provider.ErrAuthExpired},
+		{"InternalServiceError", provider.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			f := newFakeEndpoint(t, map[string]handler{
+				"secretsmanager.GetSecretValue": func(req map[string]interface{}) (int, interface{}) {
+					return awsError(tt.code)
+				},
+			})
+			p := newTestSecretsManager(t, f)
+
+			_, err := p.GetSecret(context.Background(), "us-east-1", "db")
+			if provider.KindOf(err) != tt.kind {
+				t.Errorf("error %v has kind %v, want %v", err, provider.KindOf(err), tt.kind)
+			}
+		})
+	}
+}
+
+func TestSecretsManagerUnconfiguredRegion(t *testing.T) {
+	f := newFakeEndpoint(t, nil)
+	p := newTestSecretsManager(t, f)
+
+	_, err := p.GetSecret(context.Background(), "ap-south-1", "db")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("GetSecret error %v is not not found", err)
+	}
+
+	_, err = p.ListSecrets(context.Background(), "not-a-region")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("ListSecrets error %v is not not found", err)
+	}
+
+	if n := len(f.received()); n != 0 {
+		t.Errorf("unconfigured regions made %d API calls, want none", n)
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]string
+	}{
+		{"plain", nil},
+		{"[1, 2]", nil},
+		{"{not json", nil},
+		{`{"user":"app","port":5432,"tls":true,"opts":{"a":1}}`, map[string]string{"user": "app", "port": "5432", "tls": "true", "opts": `{"a":1}`}},
+	}
+	for _, tt := range tests {
+		got := jsonFields(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("jsonFields(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for key, value := range tt.want {
+			if got[key] != value {
+				t.Errorf("jsonFields(%q)[%s] = %q, want %q", tt.value, key, got[key], value)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// settings holds the instance options shared by the AWS providers
+type settings struct {
+	profile  string
+	regions  []string
+	roleARN  string
+	endpoint string   // Custom endpoint, e.g. LocalStack or moto
+	paths    []string // SSM path prefixes
+}
+
+// readSettings reads the AWS options from a provider config
+func readSettings(cfg *provider.Config) settings {
+	var s settings
+	if cfg == nil || cfg.Settings == nil {
+		return s
+	}
+	if v, ok := cfg.Settings["profile"].(string); ok {
+		s.profile = v
+	}
+	if v, ok := cfg.Settings["regions"].([]string); ok {
+		s.regions = v
+	}
+	if v, ok := cfg.Settings["role_arn"].(string); ok {
+		s.roleARN = v
+	}
+	if v, ok := cfg.Settings["endpoint"].(string); ok {
+		s.endpoint = v
+	}
+	if v, ok := cfg.Settings["paths"].([]string); ok {
+		s.paths = v
+	}
+	return s
+}
+
+// loadConfig resolves credentials and the default region for an instance
+// Credentials come from the named profile (or the default chain) and, with
+// a role ARN, from assuming that role.
+func loadConfig(ctx context.Context, s settings) (awssdk.Config, error) {
+	var opts []func(*config.LoadOptions) error
+	if s.profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(s.profile))
+	}
+	if len(s.regions) > 0 {
+		opts = append(opts, config.WithRegion(s.regions[0]))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return awssdk.Config{}, provider.NewError(provider.ErrAuthExpired, fmt.Errorf("failed to load AWS config: %w", err))
+	}
+	if cfg.Region == "" {
+		return awssdk.Config{}, fmt.Errorf("no AWS region configured (set regions in config, AWS_REGION or the profile's region)")
+	}
+
+	if s.roleARN != "" {
+		stsClient := sts.NewFromConfig(cfg, func(o *sts.Options) {
+			if s.endpoint != "" {
+				o.BaseEndpoint = awssdk.String(s.endpoint)
+			}
+		})
+		cfg.Credentials = awssdk.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, s.roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "smart-keyvault"
+		}))
+	}
+
+	return cfg, nil
+}
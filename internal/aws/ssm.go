@@ -0,0 +1,271 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// rootPath is the vault holding parameters outside any path hierarchy
+// ("name" or "/name"), listed under their full names
+const rootPath = "/"
+
+// SSM implements the provider.Provider interface for AWS Systems Manager
+// Parameter Store: path prefixes are vaults and parameters below them secrets
+type SSM struct {
+	client *ssm.Client
+	paths  []string
+}
+
+// SSMSchema declares the settings of a Parameter Store instance
+var SSMSchema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "profile"},
+		{Name: "regions", Type: provider.StringList},
+		{Name: "role_arn"},
+		{Name: "endpoint"},
+		{Name: "paths", Type: provider.StringList},
+	},
+	Validate: func(settings map[string]interface{}) error {
+		if regions := settings["regions"].([]string); len(regions) > 1 {
+			return fmt.Errorf("has %d regions; configure one instance per region", len(regions))
+		}
+		return nil
+	},
+}
+
+// NewSSMProvider creates a new SSM Parameter Store provider
+// Configuration options:
+//   - "profile" (string): shared config profile (optional, defaults to the default chain)
+//   - "regions" ([]string): a single region (optional, defaults to the profile's region)
+//   - "role_arn" (string): role to assume (optional)
+//   - "endpoint" (string): custom endpoint, e.g. LocalStack (optional)
+//   - "paths" ([]string): path prefixes listed as vaults (optional, defaults to every top-level path)
+func NewSSMProvider(cfg *provider.Config) (provider.Provider, error) {
+	s := readSettings(cfg)
+
+	awsCfg, err := loadConfig(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(s.paths))
+	for _, path := range s.paths {
+		paths = append(paths, vaultPath(path))
+	}
+
+	return &SSM{
+		client: ssm.NewFromConfig(awsCfg, func(o *ssm.Options) {
+			if s.endpoint != "" {
+				o.BaseEndpoint = awssdk.String(s.endpoint)
+			}
+		}),
+		paths: paths,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *SSM) Name() string {
+	return "ssm"
+}
+
+// ListVaults returns the configured path prefixes, or else every top-level
+// path plus "/" when parameters exist outside any hierarchy
+func (p *SSM) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	paths := p.paths
+	if len(paths) == 0 {
+		seen := make(map[string]bool)
+		for param, err := range p.describe(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list vaults: %w", err)
+			}
+			path := rootPath
+			if rest, ok := strings.CutPrefix(awssdk.ToString(param.Name), "/"); ok {
+				if top, _, nested := strings.Cut(rest, "/"); nested {
+					path = "/" + top
+				}
+			}
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	vaults := make([]*models.Vault, 0, len(paths))
+	for _, path := range paths {
+		vaults = append(vaults, &models.Vault{Name: path, Provider: "ssm"})
+	}
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the parameters below a path sorted by name
+func (p *SSM) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	var secrets []*models.Secret
+	for secret, err := range p.StreamSecrets(ctx, vaultName) {
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// StreamSecrets yields the parameters below a path page by page, named
+// relative to it
+func (p *SSM) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		path := vaultPath(vaultName)
+		var filters []ssmtypes.ParameterStringFilter
+		if path != rootPath {
+			filters = []ssmtypes.ParameterStringFilter{{
+				Key:    awssdk.String("Path"),
+				Option: awssdk.String("Recursive"),
+				Values: []string{path},
+			}}
+		}
+
+		for param, err := range p.describe(ctx, filters) {
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list secrets: %w", err))
+				return
+			}
+
+			name, ok := relativeName(path, awssdk.ToString(param.Name))
+			if !ok {
+				continue
+			}
+			if !yield(&models.Secret{
+				Name:      name,
+				VaultName: vaultName,
+				Provider:  "ssm",
+				Enabled:   true,
+				UpdatedOn: param.LastModifiedDate,
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetSecret retrieves the latest version of a parameter, decrypting
+// SecureStrings
+func (p *SSM) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.GetSecretVersion(ctx, vaultName, secretName, "")
+}
+
+// GetSecretVersion retrieves a parameter by version number or label
+// ("" is the latest version)
+func (p *SSM) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	fullName := absoluteName(vaultPath(vaultName), secretName)
+	selector := fullName
+	if version != "" {
+		selector += ":" + version
+	}
+
+	out, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           awssdk.String(selector),
+		WithDecryption: awssdk.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", classifyError(err))
+	}
+
+	param := out.Parameter
+	value := &models.SecretValue{
+		Name:      secretName,
+		Value:     awssdk.ToString(param.Value),
+		VaultName: vaultName,
+		Provider:  "ssm",
+		Fields:    jsonFields(awssdk.ToString(param.Value)),
+		Version:   strconv.FormatInt(param.Version, 10),
+		UpdatedOn: param.LastModifiedDate,
+		Metadata: map[string]string{
+			"arn":  awssdk.ToString(param.ARN),
+			"type": string(param.Type),
+		},
+	}
+
+	// Tags need a separate permission; a caller without it still gets the value
+	tags, err := p.client.ListTagsForResource(ctx, &ssm.ListTagsForResourceInput{
+		ResourceType: ssmtypes.ResourceTypeForTaggingParameter,
+		ResourceId:   awssdk.String(fullName),
+	})
+	if err == nil && len(tags.TagList) > 0 {
+		value.Tags = make(map[string]string, len(tags.TagList))
+		for _, t := range tags.TagList {
+			value.Tags[awssdk.ToString(t.Key)] = awssdk.ToString(t.Value)
+		}
+	}
+
+	return value, nil
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *SSM) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureVersioning, provider.FeatureTags:
+		return true
+	default:
+		return false
+	}
+}
+
+// describe yields parameter metadata page by page
+func (p *SSM) describe(ctx context.Context, filters []ssmtypes.ParameterStringFilter) iter.Seq2[ssmtypes.ParameterMetadata, error] {
+	return func(yield func(ssmtypes.ParameterMetadata, error) bool) {
+		pager := ssm.NewDescribeParametersPaginator(p.client, &ssm.DescribeParametersInput{ParameterFilters: filters})
+
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(ssmtypes.ParameterMetadata{}, classifyError(err))
+				return
+			}
+
+			for _, param := range page.Parameters {
+				if !yield(param, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// vaultPath normalizes a vault name to a parameter path ("app/" is "/app")
+func vaultPath(vault string) string {
+	if trimmed := strings.Trim(vault, "/"); trimmed != "" {
+		return "/" + trimmed
+	}
+	return rootPath
+}
+
+// relativeName returns a parameter's name within a vault path
+// The root vault holds only names outside any hierarchy, unchanged.
+func relativeName(vault, name string) (string, bool) {
+	if vault == rootPath {
+		return name, !strings.Contains(strings.TrimPrefix(name, "/"), "/")
+	}
+	return strings.CutPrefix(name, vault+"/")
+}
+
+// absoluteName returns the full parameter name of a secret in a vault path
+func absoluteName(vault, secret string) string {
+	if vault == rootPath {
+		return secret
+	}
+	return vault + "/" + strings.TrimPrefix(secret, "/")
+}
@@ -0,0 +1,217 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// newTestSSM returns a Parameter Store provider served by f
+func newTestSSM(t *testing.T, f *fakeEndpoint, paths ...string) *SSM {
+	t.Helper()
+
+	p, err := NewSSMProvider(&provider.Config{
+		Name:     "ssm",
+		Instance: "test",
+		Settings: map[string]interface{}{
+			"regions":  []string{"eu-west-1"},
+			"endpoint": f.url,
+			"paths":    paths,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*SSM)
+}
+
+// describeParameters serves DescribeParameters in two pages
+func describeParameters(names ...string) handler {
+	return func(req map[string]interface{}) (int, interface{}) {
+		half := len(names) / 2
+		page, next := names[:half], "page-2"
+		if req["NextToken"] == "page-2" {
+			page, next = names[half:], ""
+		}
+
+		params := make([]map[string]interface{}, len(page))
+		for i, name := range page {
+			params[i] = map[string]interface{}{"Name": name, "LastModifiedDate": epoch(updated)}
+		}
+		resp := map[string]interface{}{"Parameters": params}
+		if next != "" {
+			resp["NextToken"] = next
+		}
+		return 200, resp
+	}
+}
+
+func TestSSMListVaults(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"AmazonSSM.DescribeParameters": describeParameters("/app/db", "/app/api/key", "plain", "/ops/token", "/top"),
+	})
+	p := newTestSSM(t, f)
+
+	vaults, err := p.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, v := range vaults {
+		names = append(names, v.Name)
+	}
+	if got := strings.Join(names, ","); got != "/,/app,/ops" {
+		t.Errorf("vaults = %s, want /,/app,/ops", got)
+	}
+}
+
+func TestSSMListVaultsConfigured(t *testing.T) {
+	f := newFakeEndpoint(t, nil)
+	p := newTestSSM(t, f, "app/", "/ops")
+
+	vaults, err := p.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vaults) != 2 || vaults[0].Name != "/app" || vaults[1].Name != "/ops" {
+		t.Errorf("vaults = %v, want the configured paths normalized", vaults)
+	}
+	if n := len(f.received()); n != 0 {
+		t.Errorf("configured paths made %d API calls, want none", n)
+	}
+}
+
+func TestSSMListSecrets(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"AmazonSSM.DescribeParameters": describeParameters("/app/db", "/app/api/key", "/application/other"),
+	})
+	p := newTestSSM(t, f)
+
+	secrets, err := p.ListSecrets(context.Background(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+		if s.VaultName != "app" || s.UpdatedOn == nil || !s.UpdatedOn.Equal(updated) {
+			t.Errorf("secret = %+v", s)
+		}
+	}
+	if got := strings.Join(names, ","); got != "api/key,db" {
+		t.Errorf("secrets = %s, want api/key,db", got)
+	}
+
+	calls := f.received()
+	if len(calls) != 2 {
+		t.Fatalf("made %d calls, want 2 pages", len(calls))
+	}
+	filters, _ := calls[0].input["ParameterFilters"].([]interface{})
+	if len(filters) != 1 {
+		t.Fatalf("ParameterFilters = %v, want a path filter", calls[0].input["ParameterFilters"])
+	}
+	filter := filters[0].(map[string]interface{})
+	if filter["Key"] != "Path" || filter["Option"] != "Recursive" || filter["Values"].([]interface{})[0] != "/app" {
+		t.Errorf("filter = %v, want a recursive /app path filter", filter)
+	}
+}
+
+func TestSSMGetSecretVersion(t *testing.T) {
+	f := newFakeEndpoint(t, map[string]handler{
+		"AmazonSSM.GetParameter": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{
+				"Parameter": map[string]interface{}{
+					"Name":             "/app/db",
+					"Value":            `{"username":"app"}`,
+					"Version":          3,
+					"Type":             "SecureString",
+					"ARN":              "arn:aws:ssm:eu-west-1:123456789012:parameter/app/db",
+					"LastModifiedDate": epoch(updated),
+				},
+			}
+		},
+		"AmazonSSM.ListTagsForResource": func(req map[string]interface{}) (int, interface{}) {
+			return 200, map[string]interface{}{"TagList": []map[string]string{{"Key": "team", "Value": "platform"}}}
+		},
+	})
+	p := newTestSSM(t, f)
+
+	secret, err := p.GetSecretVersion(context.Background(), "/app", "db", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != `{"username":"app"}` || secret.Fields["username"] != "app" || secret.Version != "3" {
+		t.Errorf("secret = %+v", secret)
+	}
+	if secret.Metadata["type"] != "SecureString" || secret.Tags["team"] != "platform" {
+		t.Errorf("secret = %+v", secret)
+	}
+
+	calls := f.received()
+	if calls[0].input["Name"] != "/app/db:3" || calls[0].input["WithDecryption"] != true {
+		t.Errorf("GetParameter input = %v, want the versioned full name with decryption", calls[0].input)
+	}
+	if calls[1].input["ResourceId"] != "/app/db" {
+		t.Errorf("ListTagsForResource input = %v, want the unversioned name", calls[1].input)
+	}
+}
+
+func TestSSMErrors(t *testing.T) {
+	tests := []struct {
+		code string
+		kind error
+	}{
+		{"ParameterNotFound", provider.ErrNotFound},
+		{"ParameterVersionNotFound", provider.ErrNotFound},
+		{"AccessDeniedException", provider.ErrPermissionDenied},
+		{"ExpiredTokenException", provider.ErrAuthExpired},
+		{"ThrottlingException", provider.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			f := newFakeEndpoint(t, map[string]handler{
+				"AmazonSSM.GetParameter": func(req map[string]interface{}) (int, interface{}) {
+					return awsError(tt.code)
+				},
+			})
+			p := newTestSSM(t, f)
+
+			_, err := p.GetSecret(context.Background(), "/", "db")
+			if provider.KindOf(err) != tt.kind {
+				t.Errorf("error %v has kind %v, want %v", err, provider.KindOf(err), tt.kind)
+			}
+		})
+	}
+}
+
+func TestSSMNames(t *testing.T) {
+	tests := []struct {
+		vault, name  string
+		relative     string
+		inVault      bool
+		absoluteName string
+	}{
+		{"/", "plain", "plain", true, "plain"},
+		{"/", "/top", "/top", true, "/top"},
+		{"/", "/app/db", "/app/db", false, "/app/db"},
+		{"/app", "/app/db", "db", true, "/app/db"},
+		{"/app", "/app/api/key", "api/key", true, "/app/api/key"},
+		{"/app", "/application/db", "", false, ""},
+	}
+	for _, tt := range tests {
+		relative, ok := relativeName(tt.vault, tt.name)
+		if ok != tt.inVault || (ok && relative != tt.relative) {
+			t.Errorf("relativeName(%q, %q) = %q, %v; want %q, %v", tt.vault, tt.name, relative, ok, tt.relative, tt.inVault)
+		}
+		if ok {
+			if got := absoluteName(tt.vault, relative); got != tt.absoluteName {
+				t.Errorf("absoluteName(%q, %q) = %q, want %q", tt.vault, relative, got, tt.absoluteName)
+			}
+		}
+	}
+}
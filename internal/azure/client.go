@@ -3,6 +3,8 @@ package azure
 import (
 	"context"
 	"fmt"
+	"iter"
+	"sort"
 	"strings"
 	"sync"
 
@@ -50,99 +52,176 @@ func NewClient(subscriptionID string) (*Client, error) {
 
 // ListVaults returns all accessible Azure Key Vaults in the subscription
 func (c *Client) ListVaults(ctx context.Context) ([]*models.Vault, error) {
-	pager := c.vaultsClient.NewListBySubscriptionPager(nil)
-
 	var vaults []*models.Vault
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
+	for vault, err := range c.StreamVaults(ctx) {
 		if err != nil {
-			return nil, fmt.Errorf("failed to list vaults: %w", err)
+			return nil, err
 		}
+		vaults = append(vaults, vault)
+	}
+
+	// Sort by name so output is stable between runs
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+
+	return vaults, nil
+}
 
-		for _, vault := range page.Value {
-			if vault.Name == nil || vault.Location == nil || vault.ID == nil {
-				continue
+// StreamVaults yields vaults page by page as the pager returns them
+func (c *Client) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return func(yield func(*models.Vault, error) bool) {
+		pager := c.vaultsClient.NewListBySubscriptionPager(nil)
+
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list vaults: %w", classifyError(err)))
+				return
 			}
 
-			vaults = append(vaults, &models.Vault{
-				Name:     *vault.Name,
-				Provider: "azure",
-				Metadata: map[string]string{
-					"location":      *vault.Location,
-					"resourceGroup": extractResourceGroup(*vault.ID),
-				},
-			})
+			for _, vault := range page.Value {
+				if vault.Name == nil || vault.Location == nil || vault.ID == nil {
+					continue
+				}
+
+				if !yield(&models.Vault{
+					Name:     *vault.Name,
+					Provider: "azure",
+					Metadata: map[string]string{
+						"location":      *vault.Location,
+						"resourceGroup": extractResourceGroup(*vault.ID),
+					},
+				}, nil) {
+					return
+				}
+			}
 		}
 	}
-
-	return vaults, nil
 }
 
 // ListSecrets returns all secrets in a specific vault
 func (c *Client) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
-	client, err := c.getSecretsClient(vaultName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get secrets client: %w", err)
+	var secrets []*models.Secret
+	for secret, err := range c.StreamSecrets(ctx, vaultName) {
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
 	}
 
-	pager := client.NewListSecretPropertiesPager(nil)
+	// Sort by name so output is stable between runs
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
 
-	var secrets []*models.Secret
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
+	return secrets, nil
+}
+
+// StreamSecrets yields the secrets of a vault page by page as the pager returns them
+func (c *Client) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		client, err := c.getSecretsClient(vaultName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list secrets: %w", err)
+			yield(nil, fmt.Errorf("failed to get secrets client: %w", err))
+			return
 		}
 
-		for _, props := range page.Value {
-			if props.ID == nil {
-				continue
-			}
+		pager := client.NewListSecretPropertiesPager(nil)
 
-			// Check if secret is enabled
-			enabled := true
-			if props.Attributes != nil && props.Attributes.Enabled != nil {
-				enabled = *props.Attributes.Enabled
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list secrets: %w", classifyError(err)))
+				return
 			}
 
-			// Only include enabled secrets (matching current CLI behavior)
-			if enabled {
-				secrets = append(secrets, &models.Secret{
+			for _, props := range page.Value {
+				if props.ID == nil {
+					continue
+				}
+
+				// Disabled secrets are listed too; callers filter them
+				// according to filters.enabled_only
+				enabled := true
+				if props.Attributes != nil && props.Attributes.Enabled != nil {
+					enabled = *props.Attributes.Enabled
+				}
+
+				secret := &models.Secret{
 					Name:      props.ID.Name(),
 					VaultName: vaultName,
 					Provider:  "azure",
 					Enabled:   enabled,
-				})
+				}
+				if attrs := props.Attributes; attrs != nil {
+					secret.CreatedOn = attrs.Created
+					secret.UpdatedOn = attrs.Updated
+					secret.ExpiresOn = attrs.Expires
+				}
+
+				if !yield(secret, nil) {
+					return
+				}
 			}
 		}
 	}
-
-	return secrets, nil
 }
 
 // GetSecret retrieves a specific secret value
 func (c *Client) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	// An empty version gets the latest version
+	return c.GetSecretVersion(ctx, vaultName, secretName, "")
+}
+
+// GetSecretVersion retrieves a specific version of a secret value
+func (c *Client) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
 	client, err := c.getSecretsClient(vaultName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get secrets client: %w", err)
 	}
 
-	// Get secret with empty version to get the latest version
-	resp, err := client.GetSecret(ctx, secretName, "", nil)
+	resp, err := client.GetSecret(ctx, secretName, version, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get secret: %w", err)
+		return nil, fmt.Errorf("failed to get secret: %w", classifyError(err))
 	}
 
 	if resp.Value == nil {
 		return nil, fmt.Errorf("secret value is nil")
 	}
 
-	return &models.SecretValue{
+	secret := &models.SecretValue{
 		Name:      secretName,
 		Value:     *resp.Value,
 		VaultName: vaultName,
 		Provider:  "azure",
-	}, nil
+		Tags:      stringMap(resp.Tags),
+	}
+
+	if resp.ID != nil {
+		secret.Version = resp.ID.Version()
+	}
+	if resp.ContentType != nil {
+		secret.ContentType = *resp.ContentType
+	}
+	if attrs := resp.Attributes; attrs != nil {
+		secret.CreatedOn = attrs.Created
+		secret.UpdatedOn = attrs.Updated
+		secret.ExpiresOn = attrs.Expires
+	}
+
+	return secret, nil
+}
+
+// stringMap converts Azure's map of string pointers, dropping nil values
+func stringMap(m map[string]*string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if v != nil {
+			out[k] = *v
+		}
+	}
+	return out
 }
 
 // getSecretsClient retrieves or creates a secrets client for a specific vault
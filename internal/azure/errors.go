@@ -0,0 +1,61 @@
+package azure
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// classifyError maps Azure SDK errors onto provider error kinds
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		switch {
+		case respErr.StatusCode == http.StatusNotFound:
+			return provider.NewError(provider.ErrNotFound, err)
+		case respErr.StatusCode == http.StatusForbidden:
+			return provider.NewError(provider.ErrPermissionDenied, err)
+		case respErr.StatusCode == http.StatusUnauthorized:
+			return provider.NewError(provider.ErrAuthExpired, err)
+		case respErr.StatusCode == http.StatusTooManyRequests, respErr.StatusCode >= 500:
+			return provider.NewError(provider.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	var authFailed *azidentity.AuthenticationFailedError
+	var authRequired *azidentity.AuthenticationRequiredError
+	if errors.As(err, &authFailed) || errors.As(err, &authRequired) {
+		return provider.NewError(provider.ErrAuthExpired, err)
+	}
+
+	// Every credential in the DefaultAzureCredential chain was unavailable
+	// (e.g. no `az login`); the SDK does not export a type for this case.
+	if strings.Contains(err.Error(), "DefaultAzureCredential") {
+		return provider.NewError(provider.ErrAuthExpired, err)
+	}
+
+	// Vault hostnames are <name>.vault.azure.net, so an unknown host means
+	// the vault does not exist rather than a network outage
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return provider.NewError(provider.ErrNotFound, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	return err
+}
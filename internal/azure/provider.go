@@ -3,6 +3,7 @@ package azure
 import (
 	"context"
 	"fmt"
+	"iter"
 	"os"
 
 	"github.com/ylchen07/smart-keyvault/internal/provider"
@@ -14,6 +15,14 @@ type Provider struct {
 	client *Client
 }
 
+// Schema declares the settings of an Azure instance
+var Schema = &provider.Schema{
+	Enabled: true,
+	Fields: []provider.Field{
+		{Name: "subscription_id", Required: true},
+	},
+}
+
 // NewProvider creates a new Azure KeyVault provider
 // Configuration options:
 //   - "subscription_id" (string): Azure subscription ID
@@ -65,11 +74,26 @@ func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models
 	return p.client.ListSecrets(ctx, vaultName)
 }
 
+// StreamVaults yields Azure Key Vaults as pages arrive
+func (p *Provider) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return p.client.StreamVaults(ctx)
+}
+
+// StreamSecrets yields the secrets of a vault as pages arrive
+func (p *Provider) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return p.client.StreamSecrets(ctx, vaultName)
+}
+
 // GetSecret retrieves a specific secret value
 func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
 	return p.client.GetSecret(ctx, vaultName, secretName)
 }
 
+// GetSecretVersion retrieves a specific version of a secret
+func (p *Provider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	return p.client.GetSecretVersion(ctx, vaultName, secretName, version)
+}
+
 // SupportsFeature checks if the provider supports a specific feature
 func (p *Provider) SupportsFeature(feature provider.Feature) bool {
 	switch feature {
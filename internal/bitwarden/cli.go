@@ -0,0 +1,78 @@
+package bitwarden
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// cli runs the bw binary for one instance
+// The session key is passed through the environment so it never shows up
+// in the process list.
+type cli struct {
+	bin string
+	env []string // BW_SESSION / BITWARDENCLI_APPDATA_DIR for this instance
+}
+
+// run executes bw without prompting and decodes its JSON output into out
+func (c *cli) run(ctx context.Context, out any, args ...string) error {
+	args = append(args, "--nointeraction")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.bin, args...)
+	cmd.Env = append(os.Environ(), c.env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return provider.NewError(provider.ErrUnavailable, fmt.Errorf("%s not found in PATH", c.bin))
+		}
+		// bw prints some errors to stdout; values are never printed on failure
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		clear(stdout.Bytes())
+		return classify(msg, err)
+	}
+
+	defer clear(stdout.Bytes())
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("failed to parse %s output: %w", c.bin, err)
+	}
+	return nil
+}
+
+// classify maps bw's diagnostics to provider error kinds
+func classify(output string, err error) error {
+	msg := err.Error()
+	if output != "" {
+		lines := strings.Split(output, "\n")
+		msg = lines[len(lines)-1]
+	}
+	wrapped := fmt.Errorf("bw: %s", msg)
+
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "not logged in"), strings.Contains(lower, "vault is locked"),
+		strings.Contains(lower, "master password"), strings.Contains(lower, "session key is invalid"):
+		return provider.NewError(provider.ErrAuthExpired, wrapped)
+	case strings.Contains(lower, "not found"):
+		return provider.NewError(provider.ErrNotFound, wrapped)
+	case strings.Contains(lower, "do not have permission"), strings.Contains(lower, "forbidden"):
+		return provider.NewError(provider.ErrPermissionDenied, wrapped)
+	case strings.Contains(lower, "econnrefused"), strings.Contains(lower, "enotfound"),
+		strings.Contains(lower, "fetch failed"):
+		return provider.NewError(provider.ErrUnavailable, wrapped)
+	default:
+		return wrapped
+	}
+}
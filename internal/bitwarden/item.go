@@ -0,0 +1,144 @@
+package bitwarden
+
+import (
+	"fmt"
+	"time"
+)
+
+// Item types as numbered by the Bitwarden API
+const (
+	typeLogin      = 1
+	typeSecureNote = 2
+	typeCard       = 3
+	typeIdentity   = 4
+	typeSSHKey     = 5
+)
+
+// typeNames are the item types shown in metadata
+var typeNames = map[int]string{
+	typeLogin:      "login",
+	typeSecureNote: "note",
+	typeCard:       "card",
+	typeIdentity:   "identity",
+	typeSSHKey:     "ssh_key",
+}
+
+// organization is an organization as printed by `bw list organizations`
+type organization struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// item is an item as printed by `bw list items`
+type item struct {
+	ID             string     `json:"id"`
+	OrganizationID *string    `json:"organizationId"`
+	Type           int        `json:"type"`
+	Name           string     `json:"name"`
+	Notes          *string    `json:"notes"`
+	CreationDate   *time.Time `json:"creationDate"`
+	RevisionDate   *time.Time `json:"revisionDate"`
+	Fields         []struct {
+		Name  string  `json:"name"`
+		Value *string `json:"value"` // Linked fields have no value
+	} `json:"fields"`
+	Login *struct {
+		Username *string `json:"username"`
+		Password *string `json:"password"`
+		TOTP     *string `json:"totp"`
+		URIs     []struct {
+			URI *string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName *string `json:"cardholderName"`
+		Brand          *string `json:"brand"`
+		Number         *string `json:"number"`
+		ExpMonth       *string `json:"expMonth"`
+		ExpYear        *string `json:"expYear"`
+		Code           *string `json:"code"`
+	} `json:"card"`
+	Identity map[string]*string `json:"identity"`
+	SSHKey   *struct {
+		PrivateKey     *string `json:"privateKey"`
+		PublicKey      *string `json:"publicKey"`
+		KeyFingerprint *string `json:"keyFingerprint"`
+	} `json:"sshKey"`
+}
+
+// fieldMap returns an item's non-empty fields: the built-in ones of its type
+// (username, password, totp, uri, number, private_key, ...), its notes and
+// its custom fields by name
+func (it *item) fieldMap() map[string]string {
+	fields := make(map[string]string)
+	set := func(key string, value *string) {
+		if value != nil && *value != "" {
+			fields[key] = *value
+		}
+	}
+
+	if l := it.Login; l != nil {
+		set("username", l.Username)
+		set("password", l.Password)
+		set("totp", l.TOTP)
+		for i, u := range l.URIs {
+			if i == 0 {
+				set("uri", u.URI)
+			} else {
+				set(fmt.Sprintf("uri%d", i+1), u.URI)
+			}
+		}
+	}
+	if c := it.Card; c != nil {
+		set("cardholder", c.CardholderName)
+		set("brand", c.Brand)
+		set("number", c.Number)
+		set("exp_month", c.ExpMonth)
+		set("exp_year", c.ExpYear)
+		set("code", c.Code)
+	}
+	for key, value := range it.Identity {
+		set(key, value)
+	}
+	if k := it.SSHKey; k != nil {
+		set("private_key", k.PrivateKey)
+		set("public_key", k.PublicKey)
+		set("fingerprint", k.KeyFingerprint)
+	}
+	set("notes", it.Notes)
+
+	// Custom fields come last so they can override built-in names
+	for _, f := range it.Fields {
+		set(f.Name, f.Value)
+	}
+	return fields
+}
+
+// primaryValue returns a login's password, a card's number or an SSH key's
+// private key, and otherwise the item's notes
+func (it *item) primaryValue() string {
+	var value *string
+	switch {
+	case it.Login != nil && it.Login.Password != nil:
+		value = it.Login.Password
+	case it.Card != nil && it.Card.Number != nil:
+		value = it.Card.Number
+	case it.SSHKey != nil && it.SSHKey.PrivateKey != nil:
+		value = it.SSHKey.PrivateKey
+	default:
+		value = it.Notes
+	}
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// metadata returns an item's type and ID
+func (it *item) metadata() map[string]string {
+	m := map[string]string{"id": it.ID}
+	if name, ok := typeNames[it.Type]; ok {
+		m["type"] = name
+	}
+	return m
+}
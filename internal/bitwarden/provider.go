@@ -0,0 +1,186 @@
+package bitwarden
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// personalVault is the vault holding items that belong to no organization
+const personalVault = "personal"
+
+// Provider implements the provider.Provider interface for Bitwarden through
+// the bw CLI: the personal vault and each organization are vaults, items
+// secrets and item fields fields
+type Provider struct {
+	bw *cli
+}
+
+// Schema declares the settings of a Bitwarden instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "session", Secret: true},
+		{Name: "appdata_dir"},
+		{Name: "binary", Default: "bw"},
+	},
+}
+
+// NewProvider creates a new Bitwarden provider
+// Configuration options:
+//   - "session" (string): session key from `bw unlock --raw` (optional, defaults to $BW_SESSION)
+//   - "appdata_dir" (string): bw data directory, one per account (optional, defaults to bw's own)
+//   - "binary" (string): bw binary (optional, defaults to "bw")
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	bw := &cli{bin: "bw"}
+
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["session"].(string); ok && v != "" {
+			bw.env = append(bw.env, "BW_SESSION="+v)
+		}
+		if v, ok := cfg.Settings["appdata_dir"].(string); ok && v != "" {
+			bw.env = append(bw.env, "BITWARDENCLI_APPDATA_DIR="+v)
+		}
+		if v, ok := cfg.Settings["binary"].(string); ok && v != "" {
+			bw.bin = v
+		}
+	}
+
+	return &Provider{bw: bw}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "bitwarden"
+}
+
+// ListVaults returns the personal vault and the account's organizations
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	orgs, err := p.organizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+
+	vaults := []*models.Vault{{Name: personalVault, Provider: "bitwarden"}}
+	for _, org := range orgs {
+		vaults = append(vaults, &models.Vault{
+			Name:     org.Name,
+			Provider: "bitwarden",
+			Metadata: map[string]string{"id": org.ID},
+		})
+	}
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the items of a vault sorted by name
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	items, err := p.items(ctx, vaultName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	secrets := make([]*models.Secret, 0, len(items))
+	for _, it := range items {
+		secrets = append(secrets, &models.Secret{
+			Name:      it.Name,
+			VaultName: vaultName,
+			Provider:  "bitwarden",
+			Enabled:   true,
+			CreatedOn: it.CreationDate,
+			UpdatedOn: it.RevisionDate,
+		})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// GetSecret retrieves an item by exact name or ID; its fields become fields
+// bw's own lookup matches names fuzzily, so the vault's items are searched
+// and filtered here instead.
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	items, err := p.items(ctx, vaultName, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	var matches []item
+	for _, it := range items {
+		if it.Name == secretName || it.ID == secretName {
+			matches = append(matches, it)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("failed to get secret: item '%s' not found in vault '%s'", secretName, vaultName))
+	case 1:
+	default:
+		return nil, fmt.Errorf("failed to get secret: %d items named '%s' in vault '%s' (use the item ID)", len(matches), secretName, vaultName)
+	}
+
+	it := matches[0]
+	return &models.SecretValue{
+		Name:      secretName,
+		Value:     it.primaryValue(),
+		VaultName: vaultName,
+		Provider:  "bitwarden",
+		Fields:    it.fieldMap(),
+		CreatedOn: it.CreationDate,
+		UpdatedOn: it.RevisionDate,
+		Metadata:  it.metadata(),
+	}, nil
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureMetadata:
+		return true
+	default:
+		return false
+	}
+}
+
+// organizations returns the organizations the account belongs to
+func (p *Provider) organizations(ctx context.Context) ([]organization, error) {
+	var orgs []organization
+	if err := p.bw.run(ctx, &orgs, "list", "organizations"); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// items returns the items of a vault, narrowed by bw's search when search
+// is not empty
+func (p *Provider) items(ctx context.Context, vaultName, search string) ([]item, error) {
+	orgID := "null" // bw's filter for items outside any organization
+	if vaultName != personalVault {
+		orgs, err := p.organizations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		orgID = ""
+		for _, org := range orgs {
+			if org.Name == vaultName || org.ID == vaultName {
+				orgID = org.ID
+				break
+			}
+		}
+		if orgID == "" {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("vault '%s' not found", vaultName))
+		}
+	}
+
+	args := []string{"list", "items", "--organizationid", orgID}
+	if search != "" {
+		args = append(args, "--search", search)
+	}
+
+	var items []item
+	if err := p.bw.run(ctx, &items, args...); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,286 @@
+package bitwarden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/smart-keyvault/internal/fakecli"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// fakeBW installs a fake bw on PATH answering each argument list with its
+// response, recording the instance's environment variables
+func fakeBW(t *testing.T, responses map[string]fakecli.Response) func() []fakecli.Call {
+	t.Helper()
+	return fakecli.Install(t, "bw", []string{"BW_SESSION", "BITWARDENCLI_APPDATA_DIR"}, responses)
+}
+
+// newTestProvider creates a provider with the given settings
+func newTestProvider(t *testing.T, settings map[string]interface{}) *Provider {
+	t.Helper()
+	p, err := NewProvider(&provider.Config{Name: "bitwarden", Instance: "test", Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*Provider)
+}
+
+const organizationsJSON = `[{"id":"org-2","name":"Platform"},{"id":"org-1","name":"Acme"}]`
+
+const itemsJSON = `[
+  {"id":"i1","organizationId":null,"type":1,"name":"db","notes":"primary",
+   "creationDate":"2024-01-01T00:00:00Z","revisionDate":"2024-06-01T12:00:00Z",
+   "login":{"username":"app","password":"s3cret","totp":null,"uris":[{"uri":"https://db.example.com"},{"uri":"https://replica.example.com"}]},
+   "fields":[{"name":"port","value":"5432"},{"name":"linked","value":null}]},
+  {"id":"i2","organizationId":null,"type":2,"name":"db-old","notes":"fuzzy match"}
+]`
+
+func TestListVaults(t *testing.T) {
+	fakeBW(t, map[string]fakecli.Response{
+		"list organizations --nointeraction": {Stdout: organizationsJSON},
+	})
+	p := newTestProvider(t, nil)
+
+	vaults, err := p.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, v := range vaults {
+		names = append(names, v.Name)
+	}
+	if got := strings.Join(names, ","); got != "Acme,Platform,personal" {
+		t.Errorf("vaults = %s, want Acme,Platform,personal", got)
+	}
+	if vaults[0].Metadata["id"] != "org-1" {
+		t.Errorf("Acme metadata = %v", vaults[0].Metadata)
+	}
+}
+
+func TestListSecrets(t *testing.T) {
+	fakeBW(t, map[string]fakecli.Response{
+		"list organizations --nointeraction":                {Stdout: organizationsJSON},
+		"list items --organizationid org-2 --nointeraction": {Stdout: `[{"id":"i4","type":2,"name":"web"},{"id":"i3","type":1,"name":"api"}]`},
+		"list items --organizationid null --nointeraction":  {Stdout: itemsJSON},
+	})
+	p := newTestProvider(t, nil)
+
+	secrets, err := p.ListSecrets(context.Background(), "Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 || secrets[0].Name != "api" || secrets[1].Name != "web" || secrets[0].VaultName != "Platform" {
+		t.Errorf("Platform secrets = %v", secrets)
+	}
+
+	secrets, err = p.ListSecrets(context.Background(), personalVault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 || secrets[0].Name != "db" || secrets[0].UpdatedOn == nil {
+		t.Errorf("personal secrets = %v", secrets)
+	}
+
+	_, err = p.ListSecrets(context.Background(), "Unknown")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("unknown vault error %v is not not found", err)
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	fakeBW(t, map[string]fakecli.Response{
+		"list items --organizationid null --search db --nointeraction": {Stdout: itemsJSON},
+	})
+	p := newTestProvider(t, nil)
+
+	// bw's search also returns db-old; only the exact name may match
+	secret, err := p.GetSecret(context.Background(), personalVault, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "s3cret" || secret.VaultName != personalVault || secret.CreatedOn == nil {
+		t.Errorf("secret = %+v", secret)
+	}
+
+	want := map[string]string{
+		"username": "app",
+		"password": "s3cret",
+		"uri":      "https://db.example.com",
+		"uri2":     "https://replica.example.com",
+		"notes":    "primary",
+		"port":     "5432",
+	}
+	if len(secret.Fields) != len(want) {
+		t.Errorf("Fields = %v, want %v", secret.Fields, want)
+	}
+	for key, value := range want {
+		if secret.Fields[key] != value {
+			t.Errorf("Fields[%s] = %q, want %q", key, secret.Fields[key], value)
+		}
+	}
+	if secret.Metadata["id"] != "i1" || secret.Metadata["type"] != "login" {
+		t.Errorf("Metadata = %v", secret.Metadata)
+	}
+}
+
+func TestGetSecretMatching(t *testing.T) {
+	fakeBW(t, map[string]fakecli.Response{
+		"list organizations --nointeraction": {Stdout: organizationsJSON},
+		"list items --organizationid org-1 --search db --nointeraction": {Stdout: `[
+			{"id":"i5","type":2,"name":"db","notes":"first"},
+			{"id":"i6","type":2,"name":"db","notes":"second"}
+		]`},
+		"list items --organizationid org-1 --search i6 --nointeraction": {Stdout: `[{"id":"i6","type":2,"name":"db","notes":"second"}]`},
+		"list items --organizationid org-1 --search d --nointeraction":  {Stdout: `[{"id":"i5","type":2,"name":"db","notes":"first"}]`},
+	})
+	p := newTestProvider(t, nil)
+
+	_, err := p.GetSecret(context.Background(), "Acme", "db")
+	if err == nil || !strings.Contains(err.Error(), "2 items named 'db'") {
+		t.Errorf("duplicate names error = %v", err)
+	}
+
+	secret, err := p.GetSecret(context.Background(), "Acme", "i6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "second" {
+		t.Errorf("lookup by ID returned %q, want second", secret.Value)
+	}
+
+	// A fuzzy search result is not a match
+	_, err = p.GetSecret(context.Background(), "Acme", "d")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("partial name error %v is not not found", err)
+	}
+}
+
+func TestPrimaryValue(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"login", `{"type":1,"login":{"password":"pw"},"notes":"n"}`, "pw"},
+		{"card", `{"type":3,"card":{"number":"4111"},"notes":"n"}`, "4111"},
+		{"ssh key", `{"type":5,"sshKey":{"privateKey":"key"}}`, "key"},
+		{"note", `{"type":2,"notes":"n"}`, "n"},
+		{"login without password", `{"type":1,"login":{"username":"u"},"notes":"n"}`, "n"},
+		{"nothing", `{"type":2}`, ""},
+	}
+	for _, tt := range tests {
+		var it item
+		if err := json.Unmarshal([]byte(tt.json), &it); err != nil {
+			t.Fatal(err)
+		}
+		if got := it.primaryValue(); got != tt.want {
+			t.Errorf("%s: primaryValue() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFieldMapSkipsEmpty(t *testing.T) {
+	var it item
+	if err := json.Unmarshal([]byte(`{"type":3,"card":{"brand":"Visa","expMonth":"","code":"123","number":null}}`), &it); err != nil {
+		t.Fatal(err)
+	}
+	if got := it.fieldMap(); len(got) != 2 || got["brand"] != "Visa" || got["code"] != "123" {
+		t.Errorf("fieldMap() = %v, want brand and code only", got)
+	}
+}
+
+func TestSessionEnv(t *testing.T) {
+	calls := fakeBW(t, map[string]fakecli.Response{
+		"list organizations --nointeraction": {Stdout: `[]`},
+	})
+	p := newTestProvider(t, map[string]interface{}{
+		"session":     "session-key",
+		"appdata_dir": "/tmp/bw-work",
+	})
+
+	if _, err := p.ListVaults(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := calls()
+	if len(got) != 1 {
+		t.Fatalf("calls = %v", got)
+	}
+	want := "BW_SESSION=session-key BITWARDENCLI_APPDATA_DIR=/tmp/bw-work"
+	if got[0].Env != want {
+		t.Errorf("bw saw %q, want %q", got[0].Env, want)
+	}
+	// The session key must not be visible in the process list
+	if strings.Contains(got[0].Args, "session-key") {
+		t.Errorf("session passed as an argument: %s", got[0].Args)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	fakeBW(t, map[string]fakecli.Response{
+		"list items --organizationid null --search locked --nointeraction":  {Stderr: "Vault is locked.", Code: 1},
+		"list items --organizationid null --search stdout --nointeraction":  {Stdout: "You are not logged in.", Code: 1},
+		"list items --organizationid null --search garbled --nointeraction": {Stdout: "not json"},
+	})
+	p := newTestProvider(t, nil)
+
+	_, err := p.GetSecret(context.Background(), personalVault, "locked")
+	if provider.KindOf(err) != provider.ErrAuthExpired {
+		t.Errorf("locked vault error %v is not auth expired", err)
+	}
+
+	// bw prints some errors to stdout
+	_, err = p.GetSecret(context.Background(), personalVault, "stdout")
+	if provider.KindOf(err) != provider.ErrAuthExpired || !strings.Contains(err.Error(), "bw: You are not logged in.") {
+		t.Errorf("stdout error = %v, want auth expired with bw's message", err)
+	}
+
+	_, err = p.GetSecret(context.Background(), personalVault, "garbled")
+	if err == nil || !strings.Contains(err.Error(), "failed to parse bw output") {
+		t.Errorf("garbled output error = %v", err)
+	}
+}
+
+func TestMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	p := newTestProvider(t, nil)
+
+	_, err := p.ListVaults(context.Background())
+	if provider.KindOf(err) != provider.ErrUnavailable {
+		t.Errorf("missing binary error %v is not unavailable", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		output  string
+		kind    error
+		message string
+	}{
+		{"You are not logged in.", provider.ErrAuthExpired, "bw: You are not logged in."},
+		{"Vault is locked.", provider.ErrAuthExpired, ""},
+		{"Master password is required.", provider.ErrAuthExpired, ""},
+		{"The session key is invalid.", provider.ErrAuthExpired, ""},
+		{"Not found.", provider.ErrNotFound, ""},
+		{"You do not have permissions to edit this.", provider.ErrPermissionDenied, ""},
+		{"Forbidden", provider.ErrPermissionDenied, ""},
+		{"request to https://vault.example.com failed, reason: connect ECONNREFUSED", provider.ErrUnavailable, ""},
+		{"fetch failed", provider.ErrUnavailable, ""},
+		{"warning\nsomething else", nil, "bw: something else"},
+		{"", nil, "bw: exit status 1"},
+	}
+	for _, tt := range tests {
+		err := classify(tt.output, exitErr)
+		if provider.KindOf(err) != tt.kind {
+			t.Errorf("classify(%q) has kind %v, want %v", tt.output, provider.KindOf(err), tt.kind)
+		}
+		if tt.message != "" && err.Error() != tt.message {
+			t.Errorf("classify(%q) = %q, want %q", tt.output, err, tt.message)
+		}
+	}
+}
@@ -2,119 +2,116 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 )
 
-// GetAzureInstance returns an Azure instance by name
-func (c *Config) GetAzureInstance(name string) (*AzureInstance, error) {
-	if c.Providers.Azure == nil {
-		return nil, fmt.Errorf("azure provider not configured")
+// GetInstance returns a provider's instance by name
+func (c *Config) GetInstance(providerName, name string) (*Instance, error) {
+	pc := c.Providers[providerName]
+	if pc == nil {
+		return nil, fmt.Errorf("%s provider not configured", providerName)
 	}
 
-	for _, inst := range c.Providers.Azure.Instances {
+	for _, inst := range pc.Instances {
 		if inst.Name == name {
 			return &inst, nil
 		}
 	}
 
-	return nil, fmt.Errorf("azure instance '%s' not found", name)
+	return nil, fmt.Errorf("%s instance '%s' not found", providerName, name)
 }
 
-// GetDefaultAzureInstance returns the default Azure instance
-func (c *Config) GetDefaultAzureInstance() (*AzureInstance, error) {
-	if c.Providers.Azure == nil {
-		return nil, fmt.Errorf("azure provider not configured")
+// GetDefaultInstance returns a provider's default instance
+func (c *Config) GetDefaultInstance(providerName string) (*Instance, error) {
+	pc := c.Providers[providerName]
+	if pc == nil {
+		return nil, fmt.Errorf("%s provider not configured", providerName)
 	}
 
 	// Look for instance marked as default
-	for _, inst := range c.Providers.Azure.Instances {
+	for _, inst := range pc.Instances {
 		if inst.Default {
 			return &inst, nil
 		}
 	}
 
 	// If no default, return first instance
-	if len(c.Providers.Azure.Instances) > 0 {
-		return &c.Providers.Azure.Instances[0], nil
+	if len(pc.Instances) > 0 {
+		return &pc.Instances[0], nil
 	}
 
-	return nil, fmt.Errorf("no azure instances configured")
+	return nil, fmt.Errorf("no %s instances configured", providerName)
 }
 
-// ListAzureInstances returns all Azure instances
-func (c *Config) ListAzureInstances() []AzureInstance {
-	if c.Providers.Azure == nil {
-		return []AzureInstance{}
+// ListInstances returns all instances of a provider
+func (c *Config) ListInstances(providerName string) []Instance {
+	pc := c.Providers[providerName]
+	if pc == nil {
+		return []Instance{}
 	}
-	return c.Providers.Azure.Instances
+	return pc.Instances
 }
 
-// GetHashicorpInstance returns a Hashicorp Vault instance by name
-func (c *Config) GetHashicorpInstance(name string) (*HashicorpInstance, error) {
-	if c.Providers.Hashicorp == nil {
-		return nil, fmt.Errorf("hashicorp provider not configured")
-	}
+// IsProviderEnabled checks if a provider is enabled
+func (c *Config) IsProviderEnabled(providerName string) bool {
+	pc := c.Providers[providerName]
+	return pc != nil && pc.Enabled
+}
 
-	for _, inst := range c.Providers.Hashicorp.Instances {
-		if inst.Name == name {
-			return &inst, nil
+// GetEnabledProviders returns the names of enabled providers, sorted
+func (c *Config) GetEnabledProviders() []string {
+	var providers []string
+
+	for _, name := range slices.Sorted(maps.Keys(c.Providers)) {
+		if c.IsProviderEnabled(name) {
+			providers = append(providers, name)
 		}
 	}
 
-	return nil, fmt.Errorf("hashicorp instance '%s' not found", name)
+	return providers
 }
 
-// GetDefaultHashicorpInstance returns the default Hashicorp Vault instance
-func (c *Config) GetDefaultHashicorpInstance() (*HashicorpInstance, error) {
-	if c.Providers.Hashicorp == nil {
-		return nil, fmt.Errorf("hashicorp provider not configured")
-	}
+// InstanceRef identifies a single configured provider instance
+type InstanceRef struct {
+	Provider string
+	Name     string
+}
 
-	// Look for instance marked as default
-	for _, inst := range c.Providers.Hashicorp.Instances {
-		if inst.Default {
-			return &inst, nil
-		}
-	}
+// EnabledInstances returns every instance of every enabled provider
+// Instances are returned in config order, providers in GetEnabledProviders order.
+func (c *Config) EnabledInstances() []InstanceRef {
+	var refs []InstanceRef
 
-	// If no default, return first instance
-	if len(c.Providers.Hashicorp.Instances) > 0 {
-		return &c.Providers.Hashicorp.Instances[0], nil
+	for _, providerName := range c.GetEnabledProviders() {
+		for _, inst := range c.ListInstances(providerName) {
+			refs = append(refs, InstanceRef{Provider: providerName, Name: inst.Name})
+		}
 	}
 
-	return nil, fmt.Errorf("no hashicorp instances configured")
+	return refs
 }
 
-// ListHashicorpInstances returns all Hashicorp Vault instances
-func (c *Config) ListHashicorpInstances() []HashicorpInstance {
-	if c.Providers.Hashicorp == nil {
-		return []HashicorpInstance{}
+// GetAlias returns an alias by name (case-insensitive)
+func (c *Config) GetAlias(name string) (*Alias, error) {
+	alias, ok := c.Aliases[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("alias '%s' not found", name)
 	}
-	return c.Providers.Hashicorp.Instances
+	return &alias, nil
 }
 
-// IsProviderEnabled checks if a provider is enabled
-func (c *Config) IsProviderEnabled(providerName string) bool {
-	switch providerName {
-	case "azure":
-		return c.Providers.Azure != nil && c.Providers.Azure.Enabled
-	case "hashicorp":
-		return c.Providers.Hashicorp != nil && c.Providers.Hashicorp.Enabled
-	default:
-		return false
+// Validate checks that an alias points at a complete secret location
+func (a Alias) Validate() error {
+	if a.Provider == "" {
+		return fmt.Errorf("provider is required")
 	}
-}
-
-// GetEnabledProviders returns a list of enabled provider names
-func (c *Config) GetEnabledProviders() []string {
-	var providers []string
-
-	if c.Providers.Azure != nil && c.Providers.Azure.Enabled {
-		providers = append(providers, "azure")
+	if a.Vault == "" {
+		return fmt.Errorf("vault is required")
 	}
-
-	if c.Providers.Hashicorp != nil && c.Providers.Hashicorp.Enabled {
-		providers = append(providers, "hashicorp")
+	if a.Secret == "" {
+		return fmt.Errorf("secret is required")
 	}
-
-	return providers
+	return nil
 }
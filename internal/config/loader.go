@@ -2,12 +2,15 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/spf13/viper"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
 )
 
 const (
@@ -69,6 +72,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to substitute environment variables: %w", err)
 	}
 
+	// Decode provider settings against their schemas
+	if err := decodeSettings(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Validate configuration
 	if err := validate(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -107,6 +115,11 @@ func LoadFromFile(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to substitute environment variables: %w", err)
 	}
 
+	// Decode provider settings against their schemas
+	if err := decodeSettings(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Validate configuration
 	if err := validate(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -125,29 +138,49 @@ func setDefaults(v *viper.Viper) {
 	// Filters defaults
 	v.SetDefault("filters.enabled_only", true)
 
-	// Provider defaults
-	v.SetDefault("providers.azure.enabled", true)
-	v.SetDefault("providers.hashicorp.enabled", true)
+	// Provider defaults, from each registered provider's schema
+	for _, name := range provider.ListProviders() {
+		if schema := provider.GetSchema(name); schema != nil {
+			v.SetDefault("providers."+name+".enabled", schema.Enabled)
+		}
+	}
+
+	// History defaults
+	v.SetDefault("history.enabled", true)
+	v.SetDefault("history.max_entries", 500)
+
+	// Audit log defaults
+	v.SetDefault("audit_log.enabled", true)
+
+	// Agent defaults
+	v.SetDefault("agent.enabled", true)
+	v.SetDefault("agent.idle_timeout", "30m")
+	v.SetDefault("agent.metadata_ttl", "1m")
+	v.SetDefault("agent.cache.enabled", false)
+	v.SetDefault("agent.cache.ttl", "5m")
+	v.SetDefault("agent.cache.max_entries", 100)
+
+	// Lint defaults
+	v.SetDefault("lint.min_length", 16)
 }
 
 // substituteEnvVars replaces ${VAR} or $VAR patterns with environment variable values
 func substituteEnvVars(cfg *Config) error {
-	// Substitute in Azure instances
-	if cfg.Providers.Azure != nil {
-		for i := range cfg.Providers.Azure.Instances {
-			cfg.Providers.Azure.Instances[i].SubscriptionID = expandEnvVars(cfg.Providers.Azure.Instances[i].SubscriptionID)
+	// Substitute in provider settings
+	for _, pc := range cfg.Providers {
+		if pc == nil {
+			continue
 		}
-	}
-
-	// Substitute in Hashicorp instances
-	if cfg.Providers.Hashicorp != nil {
-		for i := range cfg.Providers.Hashicorp.Instances {
-			inst := &cfg.Providers.Hashicorp.Instances[i]
-			inst.Address = expandEnvVars(inst.Address)
-			inst.Token = expandEnvVars(inst.Token)
-			inst.Namespace = expandEnvVars(inst.Namespace)
+		for i := range pc.Instances {
+			expandSettings(pc.Instances[i].Settings)
 		}
 	}
+	cfg.Plugins.Dir = expandEnvVars(cfg.Plugins.Dir)
+
+	// Substitute in audit log paths
+	cfg.AuditLog.Path = expandEnvVars(cfg.AuditLog.Path)
+	cfg.AuditLog.JSONLines = expandEnvVars(cfg.AuditLog.JSONLines)
+	cfg.Agent.Socket = expandEnvVars(cfg.Agent.Socket)
 
 	return nil
 }
@@ -174,42 +207,94 @@ func expandEnvVars(s string) string {
 	})
 }
 
-// validate validates the configuration
-func validate(cfg *Config) error {
-	// Validate Azure provider instances
-	if cfg.Providers.Azure != nil && cfg.Providers.Azure.Enabled {
-		if len(cfg.Providers.Azure.Instances) == 0 {
-			return fmt.Errorf("azure provider is enabled but has no instances configured")
+// expandSettings substitutes environment variables in every string of an
+// instance's settings, including nested maps and lists
+func expandSettings(settings map[string]interface{}) {
+	for key, value := range settings {
+		settings[key] = expandValue(value)
+	}
+}
+
+// expandValue substitutes environment variables in one settings value
+func expandValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return expandEnvVars(v)
+	case map[string]interface{}:
+		expandSettings(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = expandValue(v[i])
 		}
+	}
+	return value
+}
 
-		for i, inst := range cfg.Providers.Azure.Instances {
-			if inst.Name == "" {
-				return fmt.Errorf("azure instance at index %d has no name", i)
-			}
-			if inst.SubscriptionID == "" {
-				return fmt.Errorf("azure instance '%s' has no subscription_id", inst.Name)
+// decodeSettings converts every instance's settings to the types declared
+// by its provider's schema and fills in defaults
+// Providers without a schema (plugins) keep their settings as written.
+func decodeSettings(cfg *Config) error {
+	for name, pc := range cfg.Providers {
+		schema := provider.GetSchema(name)
+		if pc == nil || schema == nil {
+			continue
+		}
+		for i := range pc.Instances {
+			inst := &pc.Instances[i]
+			settings, err := schema.Decode(inst.Settings)
+			if err != nil {
+				return fmt.Errorf("%s instance '%s': %w", name, inst.Name, err)
 			}
+			inst.Settings = settings
 		}
 	}
+	return nil
+}
 
-	// Validate Hashicorp provider instances
-	if cfg.Providers.Hashicorp != nil && cfg.Providers.Hashicorp.Enabled {
-		if len(cfg.Providers.Hashicorp.Instances) == 0 {
-			return fmt.Errorf("hashicorp provider is enabled but has no instances configured")
+// validate validates the configuration
+func validate(cfg *Config) error {
+	// Validate provider instances
+	for _, name := range slices.Sorted(maps.Keys(cfg.Providers)) {
+		pc := cfg.Providers[name]
+		if pc == nil || !pc.Enabled {
+			continue
+		}
+		if len(pc.Instances) == 0 {
+			return fmt.Errorf("%s provider is enabled but has no instances configured", name)
 		}
 
-		for i, inst := range cfg.Providers.Hashicorp.Instances {
+		schema := provider.GetSchema(name)
+		for i, inst := range pc.Instances {
 			if inst.Name == "" {
-				return fmt.Errorf("hashicorp instance at index %d has no name", i)
+				return fmt.Errorf("%s instance at index %d has no name", name, i)
 			}
-			if inst.Address == "" {
-				return fmt.Errorf("hashicorp instance '%s' has no address", inst.Name)
+			if schema == nil {
+				continue
 			}
-			if inst.Token == "" {
-				return fmt.Errorf("hashicorp instance '%s' has no token", inst.Name)
+			if err := schema.Check(inst.Settings); err != nil {
+				return fmt.Errorf("%s instance '%s' %w", name, inst.Name, err)
 			}
 		}
 	}
 
+	// Validate the agent value cache
+	if cfg.Agent.Cache.Enabled && cfg.Agent.Cache.MaxEntries <= 0 {
+		return fmt.Errorf("agent.cache.max_entries must be positive when the cache is enabled")
+	}
+
+	// Validate aliases
+	for name, alias := range cfg.Aliases {
+		if err := alias.Validate(); err != nil {
+			return fmt.Errorf("alias '%s': %w", name, err)
+		}
+	}
+
+	// Validate favorites
+	for i, fav := range cfg.Favorites {
+		if fav.Provider == "" || fav.Vault == "" || fav.Secret == "" {
+			return fmt.Errorf("favorite at index %d must set provider, vault and secret", i)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DefaultStateDir is the default directory for local state (history, logs)
+	DefaultStateDir = ".local/state/smart-keyvault"
+)
+
+// DefaultConfigPath returns the path of the default config file
+func DefaultConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultConfigDir, DefaultConfigName+".yaml"), nil
+}
+
+// PluginDir returns the default directory searched for provider plugins
+func PluginDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultConfigDir, "plugins"), nil
+}
+
+// StateDir returns the directory used for local state files
+// Honors $XDG_STATE_HOME, falling back to ~/.local/state/smart-keyvault
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "smart-keyvault"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultStateDir), nil
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+	"github.com/zalando/go-keyring"
+)
+
+// refPattern matches references to secrets in provider settings:
+// ${secret:provider/instance/vault/secret#field} and ${keyring:service/user}
+var refPattern = regexp.MustCompile(`\$\{(secret|keyring):([^}]+)\}`)
+
+// errRefCycle reports instances whose references depend on each other
+var errRefCycle = errors.New("secret reference cycle")
+
+// SecretReader reads the secret a ${secret:...} reference names; cfg is the
+// referenced instance with its own references already resolved
+type SecretReader func(ctx context.Context, cfg *provider.Config, ref models.SecretRef, field string) (*models.SecretValue, error)
+
+// SetSecretReader replaces how secret references are read, so the CLI can
+// apply the same policies and audit log as get-secret
+func (c *Config) SetSecretReader(read SecretReader) {
+	c.secretReader = read
+}
+
+// ProviderConfig returns the provider.Config of an instance, or of the
+// provider's default instance when instanceName is empty
+// Secret references in the settings are resolved here rather than when the
+// config is loaded, so only the instances actually used read their secrets.
+func (c *Config) ProviderConfig(ctx context.Context, providerName, instanceName string) (*provider.Config, error) {
+	return c.providerConfig(ctx, providerName, instanceName, nil)
+}
+
+// providerConfig resolves an instance's settings; chain lists the instances
+// whose references led here, outermost first
+func (c *Config) providerConfig(ctx context.Context, providerName, instanceName string, chain []string) (*provider.Config, error) {
+	var instance *Instance
+	var err error
+
+	if instanceName != "" {
+		instance, err = c.GetInstance(providerName, instanceName)
+	} else {
+		instance, err = c.GetDefaultInstance(providerName)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s instance: %w", providerName, err)
+	}
+
+	// Reaching an instance that is still being resolved means a cycle
+	id := providerName + "/" + instance.Name
+	chain = append(slices.Clip(chain), id)
+	if slices.Contains(chain[:len(chain)-1], id) {
+		return nil, fmt.Errorf("%w: %s", errRefCycle, strings.Join(chain, " -> "))
+	}
+
+	settings, err := c.resolveValue(ctx, instance.Settings, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &provider.Config{
+		Name:     providerName,
+		Instance: instance.Name,
+		Settings: make(map[string]interface{}),
+	}
+	if m, ok := settings.(map[string]interface{}); ok {
+		cfg.Settings = m
+	}
+	return cfg, nil
+}
+
+// resolveValue returns a copy of a settings value with every reference in
+// its strings replaced by the secret it names
+func (c *Config) resolveValue(ctx context.Context, value interface{}, chain []string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return c.resolveString(ctx, v, chain)
+	case []string:
+		list := make([]string, len(v))
+		for i, s := range v {
+			resolved, err := c.resolveString(ctx, s, chain)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := c.resolveValue(ctx, item, chain)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := c.resolveValue(ctx, item, chain)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = resolved
+		}
+		return m, nil
+	}
+	return value, nil
+}
+
+// resolveString replaces the references in one string
+func (c *Config) resolveString(ctx context.Context, s string, chain []string) (string, error) {
+	matches := refPattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s, nil
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		kind, ref := s[m[2]:m[3]], s[m[4]:m[5]]
+		value, err := c.resolveRef(ctx, kind, ref, chain)
+		if errors.Is(err, errRefCycle) {
+			// The cycle already names every instance involved
+			return "", err
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s for %s: %w", s[m[0]:m[1]], chain[len(chain)-1], err)
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(value)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// resolveRef reads the value a single reference names
+// Values are kept for the life of the process, so instances sharing a
+// credential read it once.
+func (c *Config) resolveRef(ctx context.Context, kind, ref string, chain []string) (string, error) {
+	key := kind + ":" + ref
+	if v, ok := c.resolved.Load(key); ok {
+		return v.(string), nil
+	}
+
+	var value string
+	var err error
+	if kind == "keyring" {
+		value, err = readKeyring(ref)
+	} else {
+		value, err = c.readSecret(ctx, ref, chain)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c.resolved.Store(key, value)
+	return value, nil
+}
+
+// readSecret reads provider/instance/vault/secret[#field] from a configured
+// provider, resolving that instance's own references first
+func (c *Config) readSecret(ctx context.Context, ref string, chain []string) (string, error) {
+	ref, field, _ := strings.Cut(ref, "#")
+	secretRef, err := models.ParseSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := c.providerConfig(ctx, secretRef.Provider, secretRef.Instance, chain)
+	if err != nil {
+		return "", err
+	}
+	secretRef.Instance = cfg.Instance
+
+	read := c.secretReader
+	if read == nil {
+		read = readProvider
+	}
+	secret, err := read(ctx, cfg, secretRef, field)
+	if err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		return secret.Value, nil
+	}
+	value, ok := secret.Fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' has no field '%s'", secretRef.Secret, field)
+	}
+	return value, nil
+}
+
+// readProvider reads a secret straight from the referenced provider
+func readProvider(ctx context.Context, cfg *provider.Config, ref models.SecretRef, field string) (*models.SecretValue, error) {
+	p, err := provider.GetProvider(cfg.Name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+	if closer, ok := p.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return p.GetSecret(ctx, ref.Vault, ref.Secret)
+}
+
+// readKeyring reads service/user from the OS keyring (macOS Keychain, Secret
+// Service on Linux, Windows Credential Manager)
+func readKeyring(ref string) (string, error) {
+	service, user, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || user == "" {
+		return "", fmt.Errorf("invalid keyring reference '%s' (expected service/user)", ref)
+	}
+
+	value, err := keyring.Get(service, user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", provider.NewError(provider.ErrNotFound, fmt.Errorf("keyring entry '%s' not found", ref))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring entry '%s': %w", ref, err)
+	}
+	return value, nil
+}
@@ -1,11 +1,30 @@
 package config
 
+import (
+	"sync"
+	"time"
+)
+
 // Config represents the complete application configuration
 type Config struct {
-	Defaults  Defaults            `mapstructure:"defaults"`
-	Providers Providers           `mapstructure:"providers"`
-	FZF       FZFConfig           `mapstructure:"fzf"`
-	Filters   Filters             `mapstructure:"filters"`
+	Defaults  Defaults         `mapstructure:"defaults"`
+	Providers Providers        `mapstructure:"providers"`
+	FZF       FZFConfig        `mapstructure:"fzf"`
+	Filters   Filters          `mapstructure:"filters"`
+	History   HistoryConfig    `mapstructure:"history"`
+	Favorites []Favorite       `mapstructure:"favorites"`
+	Aliases   map[string]Alias `mapstructure:"aliases"`
+	Lint      LintConfig       `mapstructure:"lint"`
+	AuditLog  AuditLogConfig   `mapstructure:"audit_log"`
+	Policies  []Policy         `mapstructure:"policies"`
+	Agent     AgentConfig      `mapstructure:"agent"`
+	Plugins   PluginsConfig    `mapstructure:"plugins"`
+
+	// resolved caches the values of secret references in provider settings
+	resolved sync.Map
+
+	// secretReader reads secret references; nil calls the provider directly
+	secretReader SecretReader
 }
 
 // Defaults holds default values for provider and vault selection
@@ -14,38 +33,28 @@ type Defaults struct {
 	Vault    string `mapstructure:"vault"`
 }
 
-// Providers holds configuration for all secret providers
-type Providers struct {
-	Azure     *AzureConfig     `mapstructure:"azure"`
-	Hashicorp *HashicorpConfig `mapstructure:"hashicorp"`
-}
+// Providers holds the configuration of every provider, by provider name
+// Built-in and plugin providers share one shape; each instance's settings
+// are checked against the schema its provider registered.
+type Providers map[string]*ProviderConfig
 
-// AzureConfig holds Azure KeyVault provider configuration
-type AzureConfig struct {
-	Enabled   bool             `mapstructure:"enabled"`
-	Instances []AzureInstance  `mapstructure:"instances"`
+// ProviderConfig holds the configuration of one provider
+type ProviderConfig struct {
+	Enabled   bool       `mapstructure:"enabled"`
+	Instances []Instance `mapstructure:"instances"`
 }
 
-// AzureInstance represents a single Azure subscription configuration
-type AzureInstance struct {
-	Name           string `mapstructure:"name"`
-	SubscriptionID string `mapstructure:"subscription_id"`
-	Default        bool   `mapstructure:"default"`
+// Instance represents a single configured instance of a provider
+// Every key besides name and default is a provider setting.
+type Instance struct {
+	Name     string                 `mapstructure:"name"`
+	Default  bool                   `mapstructure:"default"`
+	Settings map[string]interface{} `mapstructure:",remain"`
 }
 
-// HashicorpConfig holds Hashicorp Vault provider configuration
-type HashicorpConfig struct {
-	Enabled   bool                `mapstructure:"enabled"`
-	Instances []HashicorpInstance `mapstructure:"instances"`
-}
-
-// HashicorpInstance represents a single Vault server configuration
-type HashicorpInstance struct {
-	Name      string `mapstructure:"name"`
-	Address   string `mapstructure:"address"`
-	Token     string `mapstructure:"token"`
-	Namespace string `mapstructure:"namespace"`
-	Default   bool   `mapstructure:"default"`
+// PluginsConfig holds options for discovering provider plugins
+type PluginsConfig struct {
+	Dir string `mapstructure:"dir"` // Searched before PATH; defaults to ~/.config/smart-keyvault/plugins
 }
 
 // FZFConfig holds fzf-tmux display configuration
@@ -59,3 +68,85 @@ type FZFConfig struct {
 type Filters struct {
 	EnabledOnly bool `mapstructure:"enabled_only"`
 }
+
+// HistoryConfig holds options for the local usage history used by quick mode
+type HistoryConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	MaxEntries int  `mapstructure:"max_entries"`
+}
+
+// Favorite is a secret pinned to the top of the quick mode list
+type Favorite struct {
+	Provider string `mapstructure:"provider"`
+	Instance string `mapstructure:"instance"`
+	Vault    string `mapstructure:"vault"`
+	Secret   string `mapstructure:"secret"`
+}
+
+// Alias maps a short name to a secret location
+// Alias names are case-insensitive (viper lowercases map keys).
+type Alias struct {
+	Provider string `mapstructure:"provider" yaml:"provider" json:"provider"`
+	Instance string `mapstructure:"instance" yaml:"instance,omitempty" json:"instance,omitempty"`
+	Vault    string `mapstructure:"vault" yaml:"vault" json:"vault"`
+	Secret   string `mapstructure:"secret" yaml:"secret" json:"secret"`
+	Field    string `mapstructure:"field" yaml:"field,omitempty" json:"field,omitempty"`
+}
+
+// LintConfig holds options for the lint command
+type LintConfig struct {
+	Rules     map[string]bool `mapstructure:"rules"`      // Rule name -> enabled; unlisted rules are enabled
+	MinLength int             `mapstructure:"min_length"` // Minimum length of password-like values
+}
+
+// AuditLogConfig holds options for the local log of secret reads
+type AuditLogConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Path      string `mapstructure:"path"`       // Defaults to audit.log in the state directory
+	Required  bool   `mapstructure:"required"`   // Refuse to return secrets that cannot be logged
+	Syslog    bool   `mapstructure:"syslog"`     // Also send entries to the local syslog
+	JSONLines string `mapstructure:"json_lines"` // Also append entries to this JSON-lines file
+}
+
+// Policy restricts commands on matching provider instances and vaults
+// Provider, Instance and Vault are glob patterns; empty matches anything.
+// Commands are command names (e.g. "walk-secrets") or classes such as
+// "bulk-read"; "*" matches every command.
+type Policy struct {
+	Name           string   `mapstructure:"name"`
+	Provider       string   `mapstructure:"provider"`
+	Instance       string   `mapstructure:"instance"`
+	Vault          string   `mapstructure:"vault"`
+	Allow          []string `mapstructure:"allow"`            // Only these commands are allowed (empty allows all)
+	Deny           []string `mapstructure:"deny"`             // These commands are refused
+	Confirm        []string `mapstructure:"confirm"`          // These commands need interactive confirmation
+	MaxWalkSecrets int      `mapstructure:"max_walk_secrets"` // Maximum secrets read by one bulk command across all instances (0 is unlimited)
+}
+
+// AgentConfig holds options for the local agent daemon
+type AgentConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`      // Use the agent when it is running
+	Socket      string        `mapstructure:"socket"`       // Defaults to $XDG_RUNTIME_DIR/smart-keyvault/agent.sock
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"` // Stop after this long without requests (0 never stops)
+	MetadataTTL time.Duration `mapstructure:"metadata_ttl"` // Cache vault and secret listings this long (0 disables)
+	Cache       ValueCache    `mapstructure:"cache"`
+}
+
+// ValueCache holds options for the agent's in-memory cache of secret values
+type ValueCache struct {
+	Enabled    bool          `mapstructure:"enabled"`     // Off unless set
+	TTL        time.Duration `mapstructure:"ttl"`         // How long values are kept unless a rule overrides it
+	MaxEntries int           `mapstructure:"max_entries"` // Least recently used values are evicted beyond this
+	Rules      []CacheRule   `mapstructure:"rules"`       // The first matching rule applies
+}
+
+// CacheRule overrides value caching for matching secrets
+// Provider, Instance, Vault and Secret are glob patterns; empty matches anything.
+type CacheRule struct {
+	Provider string        `mapstructure:"provider"`
+	Instance string        `mapstructure:"instance"`
+	Vault    string        `mapstructure:"vault"`
+	Secret   string        `mapstructure:"secret"`
+	TTL      time.Duration `mapstructure:"ttl"`   // Overrides the default TTL
+	Never    bool          `mapstructure:"never"` // Never cache matching values
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SetAlias adds or replaces an alias in the config file at path
+// The file is edited as a YAML document so comments, ordering and unresolved
+// ${VAR} references in other sections are preserved.
+func SetAlias(path, name string, alias Alias) error {
+	if err := alias.Validate(); err != nil {
+		return err
+	}
+
+	var value yaml.Node
+	if err := value.Encode(alias); err != nil {
+		return fmt.Errorf("failed to encode alias: %w", err)
+	}
+
+	return editConfigFile(path, func(root *yaml.Node) error {
+		aliases := mappingValue(root, "aliases", true)
+		setMappingKey(aliases, strings.ToLower(name), &value)
+		return nil
+	})
+}
+
+// RemoveAlias deletes an alias from the config file at path
+func RemoveAlias(path, name string) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		aliases := mappingValue(root, "aliases", false)
+		if aliases == nil || !deleteMappingKey(aliases, strings.ToLower(name)) {
+			return fmt.Errorf("alias '%s' not found", name)
+		}
+		return nil
+	})
+}
+
+// editConfigFile applies edit to the top-level mapping of a YAML file
+// A missing file is created. The previous contents are kept as <path>.bak and
+// the new contents are written to a temporary file and renamed into place.
+func editConfigFile(path string, edit func(root *yaml.Node) error) error {
+	mode := os.FileMode(0o600)
+
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if info, statErr := os.Stat(path); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(original)) > 0 {
+		if err := yaml.Unmarshal(original, &doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a YAML mapping", path)
+	}
+
+	if err := edit(doc.Content[0]); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if len(original) > 0 {
+		if err := os.WriteFile(path+".bak", original, mode); err != nil {
+			return fmt.Errorf("failed to write config backup: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
+
+	return nil
+}
+
+// mappingValue returns the mapping stored under key, optionally creating it
+func mappingValue(mapping *yaml.Node, key string, create bool) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				if !create {
+					return nil
+				}
+				// Replace an empty or scalar value (e.g. "aliases:") with a mapping
+				*value = yaml.Node{Kind: yaml.MappingNode}
+			}
+			return value
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		value,
+	)
+	return value
+}
+
+// setMappingKey sets key to value, replacing an existing entry in place
+func setMappingKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		value,
+	)
+}
+
+// deleteMappingKey removes key from a mapping, reporting whether it existed
+func deleteMappingKey(mapping *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
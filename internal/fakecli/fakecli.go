@@ -0,0 +1,81 @@
+// Package fakecli installs fake command-line tools on PATH for tests of
+// providers that drive a CLI (op, bw)
+package fakecli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Response is the output of one fake invocation
+type Response struct {
+	Stdout string
+	Stderr string
+	Code   int
+}
+
+// Call is one recorded invocation
+type Call struct {
+	Args string
+	Env  string // The recorded variables as NAME=value, space separated
+}
+
+// Install puts a fake name script first on PATH that answers each argument
+// list with its response and fails on any other, and returns a function
+// listing the calls made
+// The variables in env are cleared for the test and recorded with each call,
+// so tests can check what the provider passes through the environment.
+func Install(t testing.TB, name string, env []string, responses map[string]Response) func() []Call {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake CLI scripts need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	recorded := make([]string, len(env))
+	for i, v := range env {
+		t.Setenv(v, "")
+		recorded[i] = v + "=$" + v
+	}
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "printf '%%s\\t%%s\\n' \"$*\" \"%s\" >> %q\n", strings.Join(recorded, " "), calls)
+	script.WriteString("case \"$*\" in\n")
+	i := 0
+	for args, resp := range responses {
+		out := filepath.Join(dir, fmt.Sprintf("%d.out", i))
+		errOut := filepath.Join(dir, fmt.Sprintf("%d.err", i))
+		if err := os.WriteFile(out, []byte(resp.Stdout), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(errOut, []byte(resp.Stderr), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&script, "%q) cat %q; cat %q >&2; exit %d ;;\n", args, out, errOut, resp.Code)
+		i++
+	}
+	script.WriteString("*) echo \"unexpected arguments: $*\" >&2; exit 99 ;;\nesac\n")
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script.String()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []Call {
+		data, _ := os.ReadFile(calls)
+		var list []Call
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if args, vars, ok := strings.Cut(line, "\t"); ok {
+				list = append(list, Call{Args: args, Env: vars})
+			}
+		}
+		return list
+	}
+}
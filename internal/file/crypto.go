@@ -0,0 +1,141 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// keys holds what a file instance needs to decrypt and encrypt vault files
+type keys struct {
+	identityPath string
+	recipients   []string
+	passphrase   string
+}
+
+// identities returns the identities used to decrypt vault files
+func (k *keys) identities() ([]age.Identity, error) {
+	if k.passphrase != "" {
+		id, err := age.NewScryptIdentity(k.passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid passphrase: %w", err)
+		}
+		return []age.Identity{id}, nil
+	}
+
+	if k.identityPath == "" {
+		return nil, provider.NewError(provider.ErrAuthExpired, errors.New("no age identity or passphrase configured"))
+	}
+
+	f, err := os.Open(k.identityPath)
+	if err != nil {
+		return nil, provider.NewError(provider.ErrAuthExpired, fmt.Errorf("failed to open age identity: %w", err))
+	}
+	defer f.Close()
+
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity %s: %w", k.identityPath, err)
+	}
+	return ids, nil
+}
+
+// encryptionRecipients returns the recipients new vault files are encrypted
+// to: the passphrase, the configured public keys, or else the public keys of
+// the configured identities
+func (k *keys) encryptionRecipients() ([]age.Recipient, error) {
+	if k.passphrase != "" {
+		r, err := age.NewScryptRecipient(k.passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid passphrase: %w", err)
+		}
+		return []age.Recipient{r}, nil
+	}
+
+	if len(k.recipients) > 0 {
+		recipients, err := age.ParseRecipients(strings.NewReader(strings.Join(k.recipients, "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipients: %w", err)
+		}
+		return recipients, nil
+	}
+
+	ids, err := k.identities()
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []age.Recipient
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient())
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no age recipients configured and none can be derived from the identity")
+	}
+	return recipients, nil
+}
+
+// decrypt decrypts an age file, armored or binary
+func (k *keys) decrypt(data []byte) ([]byte, error) {
+	ids, err := k.identities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	}
+
+	r, err := age.Decrypt(src, ids...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, provider.NewError(provider.ErrPermissionDenied, fmt.Errorf("failed to decrypt: %w", err))
+		}
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plain, nil
+}
+
+// encrypt encrypts plain to the configured recipients as an armored age file,
+// which diffs and merges as text in git
+func (k *keys) encrypt(plain []byte) ([]byte, error) {
+	recipients, err := k.encryptionRecipients()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := armored.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
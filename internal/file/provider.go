@@ -0,0 +1,279 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Provider implements the provider.Provider interface over a directory of
+// age-encrypted files, one per vault
+type Provider struct {
+	store *store
+
+	mu   sync.Mutex
+	docs map[string]*cachedDoc // Decrypted vaults, keyed by vault name
+}
+
+// cachedDoc is a decrypted vault, valid while its file is unchanged
+// Passphrase decryption is deliberately slow, so each file is decrypted
+// once per provider instance rather than once per call.
+type cachedDoc struct {
+	modTime time.Time
+	size    int64
+	doc     *document
+}
+
+// Schema declares the settings of a file instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "directory", Required: true},
+		{Name: "identity"},
+		{Name: "recipients", Type: provider.StringList},
+		{Name: "passphrase", Secret: true},
+	},
+	Validate: func(settings map[string]interface{}) error {
+		identity, passphrase := settings["identity"].(string), settings["passphrase"].(string)
+		if identity == "" && passphrase == "" {
+			return fmt.Errorf("needs an identity or a passphrase")
+		}
+		if passphrase != "" && (identity != "" || len(settings["recipients"].([]string)) > 0) {
+			return fmt.Errorf("cannot combine a passphrase with an identity or recipients")
+		}
+		return nil
+	},
+}
+
+// NewProvider creates a new encrypted file provider
+// Configuration options:
+//   - "directory" (string): directory holding <vault>.age files
+//   - "identity" (string): age identity file used to decrypt
+//   - "recipients" ([]string): age public keys new files are encrypted to (optional, defaults to the identity's)
+//   - "passphrase" (string): passphrase used instead of identity and recipients
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	k := &keys{}
+	var dir string
+
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["directory"].(string); ok {
+			dir = v
+		}
+		if v, ok := cfg.Settings["identity"].(string); ok {
+			k.identityPath = v
+		}
+		if v, ok := cfg.Settings["recipients"].([]string); ok {
+			k.recipients = v
+		}
+		if v, ok := cfg.Settings["passphrase"].(string); ok {
+			k.passphrase = v
+		}
+	}
+
+	if dir == "" {
+		return nil, fmt.Errorf("file provider requires a directory")
+	}
+	if k.identityPath == "" && k.passphrase == "" {
+		return nil, fmt.Errorf("file provider requires an identity or a passphrase")
+	}
+
+	return &Provider{
+		store: &store{dir: dir, keys: k},
+		docs:  make(map[string]*cachedDoc),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "file"
+}
+
+// ListVaults returns the vault files in the directory
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	names, err := p.store.vaults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+
+	vaults := make([]*models.Vault, 0, len(names))
+	for _, name := range names {
+		path, _ := p.store.vaultPath(name)
+		vaults = append(vaults, &models.Vault{
+			Name:     name,
+			Provider: "file",
+			Metadata: map[string]string{"path": path},
+		})
+	}
+	return vaults, nil
+}
+
+// ListSecrets returns the secrets of a vault sorted by name
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	doc, err := p.document(vaultName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	secrets := make([]*models.Secret, 0, len(doc.Secrets))
+	for _, name := range slices.Sorted(maps.Keys(doc.Secrets)) {
+		secrets = append(secrets, secretInfo(vaultName, name, doc.Secrets[name]))
+	}
+	return secrets, nil
+}
+
+// GetSecret retrieves a secret value
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	doc, err := p.document(vaultName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	e, ok := doc.Secrets[secretName]
+	if !ok {
+		return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("failed to get secret: secret not found"))
+	}
+	if e.Enabled != nil && !*e.Enabled {
+		return nil, provider.NewError(provider.ErrPermissionDenied, fmt.Errorf("failed to get secret: secret '%s' is disabled", secretName))
+	}
+
+	value := &models.SecretValue{
+		Name:        secretName,
+		Value:       primaryValue(e),
+		VaultName:   vaultName,
+		Provider:    "file",
+		Fields:      maps.Clone(e.Fields),
+		ContentType: e.ContentType,
+		CreatedOn:   e.CreatedOn,
+		UpdatedOn:   e.UpdatedOn,
+		ExpiresOn:   e.ExpiresOn,
+		Tags:        maps.Clone(e.Tags),
+	}
+	if e.Version > 0 {
+		value.Version = strconv.Itoa(e.Version)
+	}
+	return value, nil
+}
+
+// SetSecret creates or replaces a secret, creating the vault file if needed
+func (p *Provider) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	if secretName == "" {
+		return nil, fmt.Errorf("secret name is required")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Always start from the file on disk so concurrent edits are not lost
+	doc, err := p.store.read(vaultName)
+	if provider.KindOf(err) == provider.ErrNotFound {
+		doc, err = &document{Secrets: make(map[string]*entry)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set secret: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	e := &entry{
+		Value:       secret.Value,
+		Fields:      secret.Fields,
+		ContentType: secret.ContentType,
+		Tags:        secret.Tags,
+		Version:     1,
+		CreatedOn:   &now,
+		UpdatedOn:   &now,
+	}
+	if prev, ok := doc.Secrets[secretName]; ok {
+		e.Version = prev.Version + 1
+		e.Enabled = prev.Enabled
+		e.ExpiresOn = prev.ExpiresOn
+		if prev.CreatedOn != nil {
+			e.CreatedOn = prev.CreatedOn
+		}
+		// Tags and content type are kept unless new ones are given
+		if e.Tags == nil {
+			e.Tags = prev.Tags
+		}
+		if e.ContentType == "" {
+			e.ContentType = prev.ContentType
+		}
+	}
+	doc.Secrets[secretName] = e
+
+	if err := p.store.write(vaultName, doc); err != nil {
+		return nil, fmt.Errorf("failed to set secret: %w", err)
+	}
+	delete(p.docs, vaultName)
+
+	return secretInfo(vaultName, secretName, e), nil
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureMetadata, provider.FeatureTags, provider.FeatureWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// document returns a decrypted vault, reusing it while the file is unchanged
+func (p *Provider) document(vault string) (*document, error) {
+	path, err := p.store.vaultPath(vault)
+	if err != nil {
+		return nil, err
+	}
+
+	info, statErr := os.Stat(path)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.docs[vault]; ok && statErr == nil && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.doc, nil
+	}
+
+	doc, err := p.store.read(vault)
+	if err != nil {
+		return nil, err
+	}
+	if statErr == nil {
+		p.docs[vault] = &cachedDoc{modTime: info.ModTime(), size: info.Size(), doc: doc}
+	}
+	return doc, nil
+}
+
+// secretInfo describes a secret without its value
+func secretInfo(vaultName, name string, e *entry) *models.Secret {
+	return &models.Secret{
+		Name:      name,
+		VaultName: vaultName,
+		Provider:  "file",
+		Enabled:   e.Enabled == nil || *e.Enabled,
+		CreatedOn: e.CreatedOn,
+		UpdatedOn: e.UpdatedOn,
+		ExpiresOn: e.ExpiresOn,
+	}
+}
+
+// primaryValue returns an entry's value, or for multi-field entries the
+// "value" field, then "password", then the first field by name (as for KV v2)
+func primaryValue(e *entry) string {
+	if e.Value != "" || len(e.Fields) == 0 {
+		return e.Value
+	}
+	if value, ok := e.Fields["value"]; ok {
+		return value
+	}
+	if value, ok := e.Fields["password"]; ok {
+		return value
+	}
+	return e.Fields[slices.Sorted(maps.Keys(e.Fields))[0]]
+}
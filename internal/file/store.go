@@ -0,0 +1,148 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// vaultExt is the extension of encrypted vault files
+const vaultExt = ".age"
+
+// vaultNamePattern restricts vault names to safe file names
+var vaultNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// document is the decrypted content of a vault file
+type document struct {
+	Secrets map[string]*entry `yaml:"secrets"`
+}
+
+// entry is one secret of a vault file
+type entry struct {
+	Value       string            `yaml:"value,omitempty"`
+	Fields      map[string]string `yaml:"fields,omitempty"`
+	ContentType string            `yaml:"content_type,omitempty"`
+	Tags        map[string]string `yaml:"tags,omitempty"`
+	Enabled     *bool             `yaml:"enabled,omitempty"` // Defaults to true
+	Version     int               `yaml:"version,omitempty"`
+	CreatedOn   *time.Time        `yaml:"created_on,omitempty"`
+	UpdatedOn   *time.Time        `yaml:"updated_on,omitempty"`
+	ExpiresOn   *time.Time        `yaml:"expires_on,omitempty"`
+}
+
+// store reads and writes the encrypted vault files of one directory
+type store struct {
+	dir  string
+	keys *keys
+}
+
+// vaultPath returns the file of a vault after validating its name
+func (s *store) vaultPath(vault string) (string, error) {
+	if !vaultNamePattern.MatchString(vault) {
+		return "", fmt.Errorf("invalid vault name '%s' (use letters, digits, '.', '_' and '-')", vault)
+	}
+	return filepath.Join(s.dir, vault+vaultExt), nil
+}
+
+// vaults returns the names of the vault files in the directory, sorted
+func (s *store) vaults() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("directory %s does not exist", s.dir))
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), vaultExt)
+		if ok && e.Type().IsRegular() && vaultNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read decrypts and parses a vault file
+func (s *store) read(vault string) (*document, error) {
+	path, err := s.vaultPath(vault)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("vault '%s' not found", vault))
+		}
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	plain, err := s.keys.decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("vault '%s': %w", vault, err)
+	}
+	defer clear(plain)
+
+	var doc document
+	if err := yaml.Unmarshal(plain, &doc); err != nil {
+		return nil, fmt.Errorf("vault '%s' is not a valid secrets document: %w", vault, err)
+	}
+	if doc.Secrets == nil {
+		doc.Secrets = make(map[string]*entry)
+	}
+	return &doc, nil
+}
+
+// write encrypts a vault document and replaces the vault file atomically
+func (s *store) write(vault string, doc *document) error {
+	path, err := s.vaultPath(vault)
+	if err != nil {
+		return err
+	}
+
+	plain, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode vault: %w", err)
+	}
+	defer clear(plain)
+
+	data, err := s.keys.encrypt(plain)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+vault+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return nil
+}
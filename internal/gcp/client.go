@@ -0,0 +1,217 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"iter"
+	"os"
+	"path"
+	"sort"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// pageSize is how many secrets are requested per list call
+const pageSize = 250
+
+// Client wraps a Secret Manager client for a set of projects
+type Client struct {
+	sm       *secretmanager.Client
+	projects []string
+}
+
+// NewClient creates a Secret Manager client
+// Credentials come from credentialsFile (a service-account key) or else
+// Application Default Credentials. An endpoint is dialled in plaintext and
+// without credentials, for emulators and fake servers. Without projects the
+// credentials' project is used.
+func NewClient(ctx context.Context, projects []string, credentialsFile, endpoint string) (*Client, error) {
+	var opts []option.ClientOption
+	switch {
+	case endpoint != "":
+		opts = append(opts,
+			option.WithEndpoint(endpoint),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+	case credentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+
+	if len(projects) == 0 {
+		project, err := defaultProject(ctx, credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		projects = []string{project}
+	}
+
+	sm, err := secretmanager.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, classifyError(fmt.Errorf("failed to create Secret Manager client: %w", err))
+	}
+
+	return newClient(sm, projects), nil
+}
+
+// newClient wraps an existing Secret Manager client (e.g. one dialled to a
+// fake server)
+func newClient(sm *secretmanager.Client, projects []string) *Client {
+	return &Client{sm: sm, projects: projects}
+}
+
+// defaultProject returns $GOOGLE_CLOUD_PROJECT or the project of the
+// service-account key or Application Default Credentials
+func defaultProject(ctx context.Context, credentialsFile string) (string, error) {
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		return project, nil
+	}
+
+	var creds *google.Credentials
+	if credentialsFile != "" {
+		data, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		creds, err = google.CredentialsFromJSON(ctx, data, secretmanager.DefaultAuthScopes()...)
+		if err != nil {
+			return "", provider.NewError(provider.ErrAuthExpired, fmt.Errorf("failed to load credentials file: %w", err))
+		}
+	} else {
+		var err error
+		creds, err = google.FindDefaultCredentials(ctx, secretmanager.DefaultAuthScopes()...)
+		if err != nil {
+			return "", provider.NewError(provider.ErrAuthExpired, fmt.Errorf("failed to find default credentials: %w", err))
+		}
+	}
+
+	if creds.ProjectID == "" {
+		return "", fmt.Errorf("no GCP project configured (set projects in config or GOOGLE_CLOUD_PROJECT)")
+	}
+	return creds.ProjectID, nil
+}
+
+// ListVaults returns the configured projects sorted by name
+func (c *Client) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	vaults := make([]*models.Vault, 0, len(c.projects))
+	for _, project := range c.projects {
+		vaults = append(vaults, &models.Vault{Name: project, Provider: "gcp"})
+	}
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the secrets of a project sorted by name
+func (c *Client) ListSecrets(ctx context.Context, project string) ([]*models.Secret, error) {
+	var secrets []*models.Secret
+	for secret, err := range c.StreamSecrets(ctx, project) {
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// StreamSecrets yields the secrets of a project page by page
+func (c *Client) StreamSecrets(ctx context.Context, project string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		it := c.sm.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{
+			Parent:   "projects/" + project,
+			PageSize: pageSize,
+		})
+
+		for {
+			s, err := it.Next()
+			if err == iterator.Done {
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list secrets: %w", classifyError(err)))
+				return
+			}
+
+			secret := &models.Secret{
+				Name:      path.Base(s.GetName()),
+				VaultName: project,
+				Provider:  "gcp",
+				Enabled:   true,
+			}
+			if t := s.GetCreateTime(); t != nil {
+				created := t.AsTime()
+				secret.CreatedOn = &created
+			}
+			if t := s.GetExpireTime(); t != nil {
+				expires := t.AsTime()
+				secret.ExpiresOn = &expires
+			}
+			if !yield(secret, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetSecret retrieves the latest enabled version of a secret
+func (c *Client) GetSecret(ctx context.Context, project, secretName string) (*models.SecretValue, error) {
+	return c.GetSecretVersion(ctx, project, secretName, "")
+}
+
+// GetSecretVersion retrieves a version of a secret by number or alias
+// ("" is "latest")
+func (c *Client) GetSecretVersion(ctx context.Context, project, secretName, version string) (*models.SecretValue, error) {
+	if version == "" {
+		version = "latest"
+	}
+	name := fmt.Sprintf("projects/%s/secrets/%s", project, secretName)
+
+	resp, err := c.sm.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: name + "/versions/" + version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", classifyError(err))
+	}
+
+	payload := resp.GetPayload()
+	if payload.DataCrc32C != nil && int64(crc32.Checksum(payload.GetData(), crc32.MakeTable(crc32.Castagnoli))) != payload.GetDataCrc32C() {
+		return nil, fmt.Errorf("failed to get secret: payload checksum mismatch")
+	}
+
+	value := &models.SecretValue{
+		Name:      secretName,
+		Value:     string(payload.GetData()),
+		VaultName: project,
+		Provider:  "gcp",
+		Version:   path.Base(resp.GetName()),
+	}
+
+	// Labels and timestamps come from the secret itself; a caller allowed to
+	// access versions but not view the secret still gets the value
+	if s, err := c.sm.GetSecret(ctx, &secretmanagerpb.GetSecretRequest{Name: name}); err == nil {
+		if t := s.GetCreateTime(); t != nil {
+			created := t.AsTime()
+			value.CreatedOn = &created
+		}
+		if t := s.GetExpireTime(); t != nil {
+			expires := t.AsTime()
+			value.ExpiresOn = &expires
+		}
+		if len(s.GetLabels()) > 0 {
+			value.Tags = s.GetLabels()
+		}
+	}
+
+	return value, nil
+}
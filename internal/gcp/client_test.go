@@ -0,0 +1,315 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+var (
+	created = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	expires = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// fakeSecretManager is an in-process Secret Manager serving fixed secrets
+type fakeSecretManager struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+
+	pageSize int                                                     // Secrets per ListSecrets page
+	secrets  map[string]*secretmanagerpb.Secret                      // Keyed by resource name
+	versions map[string]*secretmanagerpb.AccessSecretVersionResponse // Keyed by requested version name
+	errs     map[string]error                                        // Returned for a requested resource name
+
+	mu       sync.Mutex
+	requests []string // Resource names requested, in order
+}
+
+func (f *fakeSecretManager) record(name string) error {
+	f.mu.Lock()
+	f.requests = append(f.requests, name)
+	f.mu.Unlock()
+	return f.errs[name]
+}
+
+func (f *fakeSecretManager) ListSecrets(ctx context.Context, req *secretmanagerpb.ListSecretsRequest) (*secretmanagerpb.ListSecretsResponse, error) {
+	if err := f.record(req.GetParent()); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range f.secrets {
+		if strings.HasPrefix(name, req.GetParent()+"/secrets/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if req.GetPageToken() != "" {
+		fmt.Sscan(req.GetPageToken(), &start)
+	}
+	end := min(start+f.pageSize, len(names))
+
+	resp := &secretmanagerpb.ListSecretsResponse{}
+	for _, name := range names[start:end] {
+		resp.Secrets = append(resp.Secrets, f.secrets[name])
+	}
+	if end < len(names) {
+		resp.NextPageToken = fmt.Sprint(end)
+	}
+	return resp, nil
+}
+
+func (f *fakeSecretManager) GetSecret(ctx context.Context, req *secretmanagerpb.GetSecretRequest) (*secretmanagerpb.Secret, error) {
+	if err := f.record(req.GetName()); err != nil {
+		return nil, err
+	}
+	if s, ok := f.secrets[req.GetName()]; ok {
+		return s, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "secret %s not found", req.GetName())
+}
+
+func (f *fakeSecretManager) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	if err := f.record(req.GetName()); err != nil {
+		return nil, err
+	}
+	if v, ok := f.versions[req.GetName()]; ok {
+		return v, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "version %s not found", req.GetName())
+}
+
+// newTestClient serves f in process and returns a client for projects
+func newTestClient(t *testing.T, f *fakeSecretManager, projects ...string) *Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(server, f)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///secretmanager",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm, err := secretmanager.NewClient(context.Background(), option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sm.Close() })
+
+	return newClient(sm, projects)
+}
+
+// version returns an access response whose checksum matches data
+func version(name, data string) *secretmanagerpb.AccessSecretVersionResponse {
+	crc := int64(crc32.Checksum([]byte(data), crc32.MakeTable(crc32.Castagnoli)))
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    name,
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(data), DataCrc32C: &crc},
+	}
+}
+
+// testServer returns a fake holding the secret db (two versions) in p1
+func testServer() *fakeSecretManager {
+	return &fakeSecretManager{
+		pageSize: 2,
+		secrets: map[string]*secretmanagerpb.Secret{
+			"projects/p1/secrets/db": {
+				Name:       "projects/p1/secrets/db",
+				CreateTime: timestamppb.New(created),
+				Expiration: &secretmanagerpb.Secret_ExpireTime{ExpireTime: timestamppb.New(expires)},
+				Labels:     map[string]string{"team": "platform"},
+			},
+			"projects/p1/secrets/api-key": {Name: "projects/p1/secrets/api-key"},
+			"projects/p1/secrets/tls":     {Name: "projects/p1/secrets/tls"},
+			"projects/p2/secrets/other":   {Name: "projects/p2/secrets/other"},
+		},
+		versions: map[string]*secretmanagerpb.AccessSecretVersionResponse{
+			"projects/p1/secrets/db/versions/latest": version("projects/123/secrets/db/versions/2", "new"),
+			"projects/p1/secrets/db/versions/1":      version("projects/123/secrets/db/versions/1", "old"),
+		},
+	}
+}
+
+func TestListVaults(t *testing.T) {
+	c := newTestClient(t, testServer(), "p2", "p1")
+
+	vaults, err := c.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vaults) != 2 || vaults[0].Name != "p1" || vaults[1].Name != "p2" {
+		t.Errorf("vaults = %v, want the configured projects sorted", vaults)
+	}
+}
+
+func TestListSecrets(t *testing.T) {
+	f := testServer()
+	c := newTestClient(t, f, "p1")
+
+	secrets, err := c.ListSecrets(context.Background(), "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "api-key,db,tls" {
+		t.Errorf("secrets = %s, want api-key,db,tls", got)
+	}
+
+	db := secrets[1]
+	if db.VaultName != "p1" || !db.Enabled || db.CreatedOn == nil || !db.CreatedOn.Equal(created) || db.ExpiresOn == nil || !db.ExpiresOn.Equal(expires) {
+		t.Errorf("db = %+v", db)
+	}
+
+	// Three secrets at two per page
+	if got := strings.Join(f.requests, ","); got != "projects/p1,projects/p1" {
+		t.Errorf("requests = %s, want two pages of projects/p1", got)
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	c := newTestClient(t, testServer(), "p1")
+
+	secret, err := c.GetSecret(context.Background(), "p1", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "new" || secret.Version != "2" || secret.VaultName != "p1" {
+		t.Errorf("secret = %+v", secret)
+	}
+	if secret.Tags["team"] != "platform" || secret.CreatedOn == nil || !secret.CreatedOn.Equal(created) || secret.ExpiresOn == nil {
+		t.Errorf("secret = %+v, want labels and timestamps", secret)
+	}
+}
+
+func TestGetSecretVersion(t *testing.T) {
+	f := testServer()
+	c := newTestClient(t, f, "p1")
+
+	secret, err := c.GetSecretVersion(context.Background(), "p1", "db", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "old" || secret.Version != "1" {
+		t.Errorf("secret = %+v", secret)
+	}
+	if f.requests[0] != "projects/p1/secrets/db/versions/1" {
+		t.Errorf("requested %s", f.requests[0])
+	}
+}
+
+func TestGetSecretWithoutViewAccess(t *testing.T) {
+	f := testServer()
+	f.errs = map[string]error{"projects/p1/secrets/db": status.Error(codes.PermissionDenied, "no secrets.get")}
+	c := newTestClient(t, f, "p1")
+
+	// Labels need secrets.get; the value is still returned without them
+	secret, err := c.GetSecret(context.Background(), "p1", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "new" || secret.Tags != nil || secret.CreatedOn != nil {
+		t.Errorf("secret = %+v, want the value only", secret)
+	}
+}
+
+func TestGetSecretChecksumMismatch(t *testing.T) {
+	f := testServer()
+	corrupted := version("projects/123/secrets/db/versions/2", "new")
+	corrupted.Payload.Data = []byte("nev")
+	f.versions["projects/p1/secrets/db/versions/latest"] = corrupted
+	c := newTestClient(t, f, "p1")
+
+	_, err := c.GetSecret(context.Background(), "p1", "db")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("error = %v, want a checksum mismatch", err)
+	}
+}
+
+func TestGetSecretErrors(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		kind error
+	}{
+		{codes.NotFound, provider.ErrNotFound},
+		{codes.PermissionDenied, provider.ErrPermissionDenied},
+		{codes.Unauthenticated, provider.ErrAuthExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			f := testServer()
+			f.errs = map[string]error{"projects/p1/secrets/db/versions/latest": status.Error(tt.code, "injected")}
+			c := newTestClient(t, f, "p1")
+
+			_, err := c.GetSecret(context.Background(), "p1", "db")
+			if provider.KindOf(err) != tt.kind {
+				t.Errorf("error %v has kind %v, want %v", err, provider.KindOf(err), tt.kind)
+			}
+		})
+	}
+
+	t.Run("list", func(t *testing.T) {
+		f := testServer()
+		f.errs = map[string]error{"projects/p1": status.Error(codes.PermissionDenied, "injected")}
+		c := newTestClient(t, f, "p1")
+
+		_, err := c.ListSecrets(context.Background(), "p1")
+		if provider.KindOf(err) != provider.ErrPermissionDenied {
+			t.Errorf("error %v is not permission denied", err)
+		}
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{status.Error(codes.NotFound, "x"), provider.ErrNotFound},
+		{status.Error(codes.PermissionDenied, "x"), provider.ErrPermissionDenied},
+		{status.Error(codes.Unauthenticated, "x"), provider.ErrAuthExpired},
+		{status.Error(codes.Unavailable, "x"), provider.ErrUnavailable},
+		{status.Error(codes.DeadlineExceeded, "x"), provider.ErrUnavailable},
+		{status.Error(codes.ResourceExhausted, "x"), provider.ErrUnavailable},
+		{fmt.Errorf("token: %w", &oauth2.RetrieveError{}), provider.ErrAuthExpired},
+		{errors.New("google: could not find default credentials"), provider.ErrAuthExpired},
+		{status.Error(codes.InvalidArgument, "x"), nil},
+	}
+	for _, tt := range tests {
+		if got := provider.KindOf(classifyError(tt.err)); got != tt.kind {
+			t.Errorf("classifyError(%v) has kind %v, want %v", tt.err, got, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gcp
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// classifyError maps Secret Manager (gRPC) errors onto provider error kinds
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound:
+		return provider.NewError(provider.ErrNotFound, err)
+	case codes.PermissionDenied:
+		return provider.NewError(provider.ErrPermissionDenied, err)
+	case codes.Unauthenticated:
+		return provider.NewError(provider.ErrAuthExpired, err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	// The token source could not refresh (e.g. revoked `gcloud auth
+	// application-default login` credentials)
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) || strings.Contains(err.Error(), "could not find default credentials") {
+		return provider.NewError(provider.ErrAuthExpired, err)
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Provider implements the provider.Provider interface for Google Cloud
+// Secret Manager: projects are vaults and secrets are secrets
+type Provider struct {
+	client *Client
+}
+
+// Schema declares the settings of a GCP instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "projects", Type: provider.StringList},
+		{Name: "credentials_file"},
+		{Name: "endpoint"},
+	},
+}
+
+// NewProvider creates a new GCP Secret Manager provider
+// Configuration options:
+//   - "projects" ([]string): project IDs listed as vaults (optional, defaults to the credentials' project)
+//   - "credentials_file" (string): service-account key file (optional, defaults to Application Default Credentials)
+//   - "endpoint" (string): plaintext gRPC address of an emulator or fake server (optional)
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	var projects []string
+	var credentialsFile, endpoint string
+
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["projects"].([]string); ok {
+			projects = v
+		}
+		if v, ok := cfg.Settings["credentials_file"].(string); ok {
+			credentialsFile = v
+		}
+		if v, ok := cfg.Settings["endpoint"].(string); ok {
+			endpoint = v
+		}
+	}
+
+	client, err := NewClient(context.Background(), projects, credentialsFile, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCP client: %w", err)
+	}
+
+	return &Provider{
+		client: client,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "gcp"
+}
+
+// ListVaults returns the configured projects
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	return p.client.ListVaults(ctx)
+}
+
+// ListSecrets returns all secrets in a project
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	return p.client.ListSecrets(ctx, vaultName)
+}
+
+// StreamSecrets yields the secrets of a project as pages arrive
+func (p *Provider) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return p.client.StreamSecrets(ctx, vaultName)
+}
+
+// GetSecret retrieves the latest version of a secret
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.client.GetSecret(ctx, vaultName, secretName)
+}
+
+// GetSecretVersion retrieves a specific version of a secret
+func (p *Provider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	return p.client.GetSecretVersion(ctx, vaultName, secretName, version)
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureVersioning, provider.FeatureTags:
+		return true
+	default:
+		return false
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 
 	vault "github.com/hashicorp/vault/api"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
 )
 
 // Client wraps the HashiCorp Vault API client
@@ -69,7 +71,7 @@ func NewClient(address, token, namespace string) (*Client, error) {
 func (c *Client) ListMounts(ctx context.Context) (map[string]*vault.MountOutput, error) {
 	mounts, err := c.client.Sys().ListMountsWithContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list mounts: %w", err)
+		return nil, fmt.Errorf("failed to list mounts: %w", classifyError(err))
 	}
 	return mounts, nil
 }
@@ -81,7 +83,7 @@ func (c *Client) ListSecrets(ctx context.Context, mountPath, secretPath string)
 
 	secret, err := c.client.Logical().ListWithContext(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list secrets: %w", err)
+		return nil, fmt.Errorf("failed to list secrets: %w", classifyError(err))
 	}
 
 	// No secrets found
@@ -98,33 +100,65 @@ func (c *Client) ListSecrets(ctx context.Context, mountPath, secretPath string)
 }
 
 // GetSecret retrieves a secret value from a KV v2 mount
-func (c *Client) GetSecret(ctx context.Context, mountPath, secretPath string) (map[string]interface{}, error) {
+// Returns the secret's key/value data and the version metadata
+// (version, created_time, custom_metadata, ...).
+func (c *Client) GetSecret(ctx context.Context, mountPath, secretPath string) (map[string]interface{}, map[string]interface{}, error) {
+	return c.GetSecretVersion(ctx, mountPath, secretPath, "")
+}
+
+// GetSecretVersion retrieves a specific version of a secret ("" is current)
+func (c *Client) GetSecretVersion(ctx context.Context, mountPath, secretPath, version string) (map[string]interface{}, map[string]interface{}, error) {
 	// For KV v2, we need to use the data path
 	path := fmt.Sprintf("%sdata/%s", mountPath, secretPath)
 
-	secret, err := c.client.Logical().ReadWithContext(ctx, path)
+	var query map[string][]string
+	if version != "" {
+		query = map[string][]string{"version": {version}}
+	}
+
+	secret, err := c.client.Logical().ReadWithDataWithContext(ctx, path, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read secret: %w", err)
+		return nil, nil, fmt.Errorf("failed to read secret: %w", classifyError(err))
 	}
 
 	if secret == nil || secret.Data == nil {
-		return nil, fmt.Errorf("secret not found")
+		return nil, nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("secret not found"))
 	}
 
 	// KV v2 stores the actual secret data under the "data" key
 	data, ok := secret.Data["data"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid secret data format")
+		return nil, nil, fmt.Errorf("invalid secret data format")
+	}
+
+	// Version metadata is optional; older servers may omit it
+	metadata, _ := secret.Data["metadata"].(map[string]interface{})
+
+	return data, metadata, nil
+}
+
+// GetMetadata reads a secret's KV v2 metadata (timestamps, versions and
+// custom_metadata) without reading its value
+func (c *Client) GetMetadata(ctx context.Context, mountPath, secretPath string) (map[string]interface{}, error) {
+	path := fmt.Sprintf("%smetadata/%s", mountPath, secretPath)
+
+	secret, err := c.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret metadata: %w", classifyError(err))
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("secret not found"))
 	}
 
-	return data, nil
+	return secret.Data, nil
 }
 
 // Health checks the health of the Vault server
 func (c *Client) Health(ctx context.Context) error {
 	health, err := c.client.Sys().HealthWithContext(ctx)
 	if err != nil {
-		return fmt.Errorf("vault health check failed: %w", err)
+		return fmt.Errorf("vault health check failed: %w", classifyError(err))
 	}
 
 	if !health.Initialized {
@@ -132,7 +166,7 @@ func (c *Client) Health(ctx context.Context) error {
 	}
 
 	if health.Sealed {
-		return fmt.Errorf("vault is sealed")
+		return provider.ErrSealed
 	}
 
 	return nil
@@ -0,0 +1,49 @@
+package hashicorp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// classifyError maps Vault API errors onto provider error kinds
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var respErr *vault.ResponseError
+	if errors.As(err, &respErr) {
+		messages := strings.ToLower(strings.Join(respErr.Errors, " "))
+
+		switch {
+		case respErr.StatusCode == http.StatusNotFound:
+			return provider.NewError(provider.ErrNotFound, err)
+		case respErr.StatusCode == http.StatusUnauthorized:
+			return provider.NewError(provider.ErrAuthExpired, err)
+		case respErr.StatusCode == http.StatusForbidden:
+			// Vault answers 403 both for bad tokens and for missing policy grants
+			if strings.Contains(messages, "invalid token") || strings.Contains(messages, "token expired") {
+				return provider.NewError(provider.ErrAuthExpired, err)
+			}
+			return provider.NewError(provider.ErrPermissionDenied, err)
+		case respErr.StatusCode == http.StatusServiceUnavailable && strings.Contains(messages, "sealed"):
+			return provider.NewError(provider.ErrSealed, err)
+		case respErr.StatusCode == http.StatusTooManyRequests, respErr.StatusCode >= 500:
+			return provider.NewError(provider.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	return err
+}
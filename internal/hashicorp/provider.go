@@ -3,7 +3,9 @@ package hashicorp
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ylchen07/smart-keyvault/internal/provider"
 	"github.com/ylchen07/smart-keyvault/pkg/models"
@@ -14,6 +16,16 @@ type Provider struct {
 	client *Client
 }
 
+// Schema declares the settings of a Vault instance
+var Schema = &provider.Schema{
+	Enabled: true,
+	Fields: []provider.Field{
+		{Name: "address", Required: true},
+		{Name: "token", Required: true, Secret: true},
+		{Name: "namespace"},
+	},
+}
+
 // NewProvider creates a new HashiCorp Vault provider
 // Configuration options:
 //   - "address" (string): Vault server address
@@ -86,6 +98,9 @@ func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
 		}
 	}
 
+	// Mounts come from a map, so sort them for stable output
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+
 	return vaults, nil
 }
 
@@ -122,44 +137,136 @@ func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models
 		})
 	}
 
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+
 	return secrets, nil
 }
 
 // GetSecret retrieves a specific secret value from a KV v2 mount
 func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.GetSecretVersion(ctx, vaultName, secretName, "")
+}
+
+// GetSecretVersion retrieves a specific version of a secret ("" is current)
+func (p *Provider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
 	// Ensure vaultName ends with /
 	if !strings.HasSuffix(vaultName, "/") {
 		vaultName = vaultName + "/"
 	}
 
-	data, err := p.client.GetSecret(ctx, vaultName, secretName)
+	data, metadata, err := p.client.GetSecretVersion(ctx, vaultName, secretName, version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get secret: %w", err)
 	}
 
 	// For KV v2, secrets can have multiple key-value pairs
 	// We'll return the first value found, or a specific key if it exists
-	// Priority: "value" > "password" > first key found
+	// Priority: "value" > "password" > first key in alphabetical order
 	var value string
 
 	if v, ok := data["value"]; ok {
 		value = fmt.Sprintf("%v", v)
 	} else if v, ok := data["password"]; ok {
 		value = fmt.Sprintf("%v", v)
-	} else {
-		// Return the first value found
-		for _, v := range data {
-			value = fmt.Sprintf("%v", v)
-			break
+	} else if len(data) > 0 {
+		// Return the value of the first key in sorted order (map order is random)
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
 		}
+		sort.Strings(keys)
+		value = fmt.Sprintf("%v", data[keys[0]])
 	}
 
-	return &models.SecretValue{
+	// Expose every key so callers can select a specific field
+	fields := make(map[string]string, len(data))
+	for k, v := range data {
+		fields[k] = fmt.Sprintf("%v", v)
+	}
+
+	secret := &models.SecretValue{
 		Name:      secretName,
 		Value:     value,
 		VaultName: strings.TrimSuffix(vaultName, "/"),
 		Provider:  "hashicorp",
-	}, nil
+		Fields:    fields,
+	}
+
+	// Version metadata from the KV v2 data response
+	if v, ok := metadata["version"]; ok && v != nil {
+		secret.Version = fmt.Sprintf("%v", v)
+	}
+	// created_time is when this version was written, i.e. the last update
+	secret.UpdatedOn = parseTime(metadata["created_time"])
+	if custom, ok := metadata["custom_metadata"].(map[string]interface{}); ok {
+		secret.Metadata = make(map[string]string, len(custom))
+		for k, v := range custom {
+			secret.Metadata[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return secret, nil
+}
+
+// GetSecretMetadata returns a secret's timestamps from its KV v2 metadata
+// The value is never read. ExpiresOn is set when the secret has
+// delete_version_after configured, i.e. when the current version will be
+// deleted automatically.
+func (p *Provider) GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error) {
+	// Ensure vaultName ends with /
+	if !strings.HasSuffix(vaultName, "/") {
+		vaultName = vaultName + "/"
+	}
+
+	metadata, err := p.client.GetMetadata(ctx, vaultName, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret metadata: %w", err)
+	}
+
+	secret := &models.Secret{
+		Name:      secretName,
+		VaultName: strings.TrimSuffix(vaultName, "/"),
+		Provider:  "hashicorp",
+		Enabled:   true,
+		CreatedOn: parseTime(metadata["created_time"]),
+		UpdatedOn: parseTime(metadata["updated_time"]),
+	}
+
+	// updated_time also changes on metadata-only edits, so prefer the
+	// creation time of the current version as the last rotation
+	if versions, ok := metadata["versions"].(map[string]interface{}); ok {
+		current := fmt.Sprintf("%v", metadata["current_version"])
+		if v, ok := versions[current].(map[string]interface{}); ok {
+			if t := parseTime(v["created_time"]); t != nil {
+				secret.UpdatedOn = t
+			}
+		}
+	}
+
+	// Versions are deleted delete_version_after their creation ("0s" disables it)
+	if s, ok := metadata["delete_version_after"].(string); ok {
+		if ttl, err := time.ParseDuration(s); err == nil && ttl > 0 && secret.UpdatedOn != nil {
+			expires := secret.UpdatedOn.Add(ttl)
+			secret.ExpiresOn = &expires
+		}
+	}
+
+	return secret, nil
+}
+
+// parseTime parses an RFC 3339 timestamp from Vault metadata
+// Returns nil for missing or empty values.
+func parseTime(v interface{}) *time.Time {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil
+	}
+	return &t
 }
 
 // SupportsFeature checks if the provider supports a specific feature
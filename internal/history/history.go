@@ -0,0 +1,159 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+const (
+	// DefaultFileName is the history file name inside the state directory
+	DefaultFileName = "history.json"
+	// DefaultMaxEntries is used when no limit is configured
+	DefaultMaxEntries = 500
+)
+
+// Entry records how often and how recently a secret was used
+// Only the reference is stored, never the secret value.
+type Entry struct {
+	models.SecretRef
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Store is the on-disk usage history
+type Store struct {
+	path    string
+	Entries []*Entry `json:"entries"`
+}
+
+// Load reads the history file at path
+// A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse history file %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+// Record bumps the usage count and last-used time of a reference
+func (s *Store) Record(ref models.SecretRef, now time.Time) {
+	for _, e := range s.Entries {
+		if e.SecretRef == ref {
+			e.Count++
+			e.LastUsed = now
+			return
+		}
+	}
+
+	s.Entries = append(s.Entries, &Entry{SecretRef: ref, Count: 1, LastUsed: now})
+}
+
+// Ranked returns entries ordered by frecency score (highest first)
+// Ties are broken by most recent use, then by reference for stable output.
+func (s *Store) Ranked(now time.Time) []*Entry {
+	ranked := make([]*Entry, len(s.Entries))
+	copy(ranked, s.Entries)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := Score(ranked[i], now), Score(ranked[j], now)
+		if si != sj {
+			return si > sj
+		}
+		if !ranked[i].LastUsed.Equal(ranked[j].LastUsed) {
+			return ranked[i].LastUsed.After(ranked[j].LastUsed)
+		}
+		return ranked[i].String() < ranked[j].String()
+	})
+
+	return ranked
+}
+
+// Prune keeps only the maxEntries highest-ranked entries
+func (s *Store) Prune(maxEntries int, now time.Time) {
+	if maxEntries <= 0 || len(s.Entries) <= maxEntries {
+		return
+	}
+	s.Entries = s.Ranked(now)[:maxEntries]
+}
+
+// Clear removes all entries
+func (s *Store) Clear() {
+	s.Entries = nil
+}
+
+// Save writes the store back to disk
+// The file is written to a temporary file and renamed into place so a crash
+// never leaves a truncated history behind.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".history-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+
+	return nil
+}
+
+// Score computes the frecency of an entry
+// Frequency is weighted by how recently the secret was last used, so a secret
+// copied ten times last month ranks below one copied three times today.
+func Score(e *Entry, now time.Time) float64 {
+	age := now.Sub(e.LastUsed)
+
+	var weight float64
+	switch {
+	case age < 4*time.Hour:
+		weight = 100
+	case age < 24*time.Hour:
+		weight = 80
+	case age < 3*24*time.Hour:
+		weight = 60
+	case age < 7*24*time.Hour:
+		weight = 40
+	case age < 30*24*time.Hour:
+		weight = 20
+	default:
+		weight = 10
+	}
+
+	return float64(e.Count) * weight
+}
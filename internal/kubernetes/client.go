@@ -0,0 +1,285 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// pageSize is how many objects are requested per list call
+const pageSize = 250
+
+// valueKey is the data key used for secrets written with a single value
+const valueKey = "value"
+
+// secretsResource is the resource listed through the metadata client
+var secretsResource = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// Client wraps a Kubernetes clientset for one kubeconfig context
+type Client struct {
+	clientset  kubernetes.Interface
+	metadata   metadata.Interface // Lists Secrets without downloading their data
+	namespace  string             // The context's namespace, listed when namespaces cannot be
+	namespaces []string           // Configured namespaces; empty lists all
+}
+
+// NewClient creates a client for a kubeconfig context
+// An empty kubeconfig uses $KUBECONFIG or ~/.kube/config, and an empty
+// contextName the current context.
+func NewClient(kubeconfig, contextName string, namespaces []string) (*Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, provider.NewError(provider.ErrAuthExpired, fmt.Errorf("failed to load kubeconfig: %w", err))
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig namespace: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	metadataClient, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes metadata client: %w", err)
+	}
+
+	return newClient(clientset, metadataClient, namespace, namespaces), nil
+}
+
+// newClient wraps existing clients (e.g. fake ones)
+func newClient(clientset kubernetes.Interface, metadataClient metadata.Interface, namespace string, namespaces []string) *Client {
+	return &Client{clientset: clientset, metadata: metadataClient, namespace: namespace, namespaces: namespaces}
+}
+
+// ListVaults returns the namespaces sorted by name
+func (c *Client) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	var vaults []*models.Vault
+	for vault, err := range c.StreamVaults(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		vaults = append(vaults, vault)
+	}
+
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// StreamVaults yields namespaces page by page
+// Users who may not list namespaces get the configured namespaces, or else
+// their context's namespace.
+func (c *Client) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return func(yield func(*models.Vault, error) bool) {
+		if len(c.namespaces) > 0 {
+			for _, ns := range c.namespaces {
+				if !yield(&models.Vault{Name: ns, Provider: "kubernetes"}, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		opts := metav1.ListOptions{Limit: pageSize}
+		for {
+			list, err := c.clientset.CoreV1().Namespaces().List(ctx, opts)
+			if apierrors.IsForbidden(err) && opts.Continue == "" {
+				yield(&models.Vault{Name: c.namespace, Provider: "kubernetes"}, nil)
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list vaults: %w", classifyError(err)))
+				return
+			}
+
+			for _, ns := range list.Items {
+				vault := &models.Vault{Name: ns.Name, Provider: "kubernetes"}
+				if ns.Status.Phase != "" {
+					vault.Metadata = map[string]string{"phase": string(ns.Status.Phase)}
+				}
+				if !yield(vault, nil) {
+					return
+				}
+			}
+
+			if list.Continue == "" {
+				return
+			}
+			opts.Continue = list.Continue
+		}
+	}
+}
+
+// ListSecrets returns the Secret objects of a namespace sorted by name
+func (c *Client) ListSecrets(ctx context.Context, namespace string) ([]*models.Secret, error) {
+	var secrets []*models.Secret
+	for secret, err := range c.StreamSecrets(ctx, namespace) {
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// StreamSecrets yields the Secret objects of a namespace page by page
+// Only object metadata is listed, so secret data never leaves the API server.
+func (c *Client) StreamSecrets(ctx context.Context, namespace string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		opts := metav1.ListOptions{Limit: pageSize}
+		for {
+			list, err := c.metadata.Resource(secretsResource).Namespace(namespace).List(ctx, opts)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to list secrets: %w", classifyError(err)))
+				return
+			}
+
+			for i := range list.Items {
+				if !yield(secretInfo(&list.Items[i]), nil) {
+					return
+				}
+			}
+
+			if list.Continue == "" {
+				return
+			}
+			opts.Continue = list.Continue
+		}
+	}
+}
+
+// GetSecret reads a Secret object; its data keys become fields
+func (c *Client) GetSecret(ctx context.Context, namespace, name string) (*models.SecretValue, error) {
+	s, err := c.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", classifyError(err))
+	}
+
+	// The API server returns data base64-encoded; the clientset has decoded it
+	fields := make(map[string]string, len(s.Data))
+	for key, value := range s.Data {
+		fields[key] = string(value)
+	}
+
+	info := secretInfo(s)
+	value := &models.SecretValue{
+		Name:      s.Name,
+		Value:     primaryValue(fields),
+		VaultName: namespace,
+		Provider:  "kubernetes",
+		Fields:    fields,
+		Version:   s.ResourceVersion,
+		CreatedOn: info.CreatedOn,
+		Tags:      maps.Clone(s.Labels),
+	}
+	if s.Type != "" {
+		value.Metadata = map[string]string{"type": string(s.Type)}
+	}
+	return value, nil
+}
+
+// SetSecret creates an Opaque Secret or replaces the data of an existing one
+// A single value without fields is stored under "value", or under the only
+// key of an existing single-key Secret. Tags are merged into the labels.
+func (c *Client) SetSecret(ctx context.Context, namespace, name string, secret *models.SecretValue) (*models.Secret, error) {
+	secrets := c.clientset.CoreV1().Secrets(namespace)
+
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		existing, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set secret: %w", classifyError(err))
+	}
+
+	data := make(map[string][]byte, len(secret.Fields)+1)
+	for key, value := range secret.Fields {
+		data[key] = []byte(value)
+	}
+	if secret.Value != "" || len(data) == 0 {
+		key := valueKey
+		if existing != nil && len(existing.Data) == 1 && len(secret.Fields) == 0 {
+			key = slices.Collect(maps.Keys(existing.Data))[0]
+		}
+		data[key] = []byte(secret.Value)
+	}
+
+	var saved *corev1.Secret
+	if existing == nil {
+		saved, err = secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: secret.Tags},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}, metav1.CreateOptions{})
+	} else {
+		updated := existing.DeepCopy()
+		updated.Data = data
+		updated.StringData = nil
+		if len(secret.Tags) > 0 {
+			if updated.Labels == nil {
+				updated.Labels = make(map[string]string)
+			}
+			maps.Copy(updated.Labels, secret.Tags)
+		}
+		// The resource version from Get makes a concurrent change fail with a conflict
+		saved, err = secrets.Update(ctx, updated, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set secret: %w", classifyError(err))
+	}
+	return secretInfo(saved), nil
+}
+
+// secretInfo describes a Secret object (or its metadata) without its data
+func secretInfo(s metav1.Object) *models.Secret {
+	created := s.GetCreationTimestamp()
+	secret := &models.Secret{
+		Name:      s.GetName(),
+		VaultName: s.GetNamespace(),
+		Provider:  "kubernetes",
+		Enabled:   true,
+	}
+	if !created.IsZero() {
+		createdOn := created.UTC()
+		secret.CreatedOn = &createdOn
+	}
+	return secret
+}
+
+// primaryValue returns the value of a single-key Secret, or else the
+// "value" key, then "password", then the first key by name
+func primaryValue(fields map[string]string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	if value, ok := fields[valueKey]; ok {
+		return value
+	}
+	if value, ok := fields["password"]; ok {
+		return value
+	}
+	return fields[slices.Sorted(maps.Keys(fields))[0]]
+}
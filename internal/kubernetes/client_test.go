@@ -0,0 +1,314 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+var created = metav1.NewTime(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+// newTestClient returns a client over fake clientsets holding objects
+func newTestClient(objects ...runtime.Object) (*Client, *fake.Clientset, *metadatafake.FakeMetadataClient) {
+	clientset := fake.NewClientset(objects...)
+	metadataClient := metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme())
+	return newClient(clientset, metadataClient, "default", nil), clientset, metadataClient
+}
+
+// secretMeta returns the metadata of a Secret as listed by the metadata client
+func secretMeta(namespace, name string) metav1.PartialObjectMetadata {
+	return metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: created},
+	}
+}
+
+func TestListVaultsPaging(t *testing.T) {
+	c, clientset, _ := newTestClient()
+
+	pages := map[string]*corev1.NamespaceList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "prod"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			},
+		},
+		"page-2": {
+			Items: []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "ci"}}},
+		},
+	}
+	clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).ListOptions
+		if opts.Limit != pageSize {
+			t.Errorf("Limit = %d, want %d", opts.Limit, pageSize)
+		}
+		page, ok := pages[opts.Continue]
+		if !ok {
+			t.Fatalf("unexpected continue token %q", opts.Continue)
+		}
+		return true, page, nil
+	})
+
+	vaults, err := c.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vaultNames(vaults); got != "ci,dev,prod" {
+		t.Errorf("vaults = %s, want ci,dev,prod", got)
+	}
+	if vaults[2].Metadata["phase"] != "Active" {
+		t.Errorf("prod metadata = %v, want phase Active", vaults[2].Metadata)
+	}
+}
+
+func TestListVaultsForbidden(t *testing.T) {
+	c, clientset, _ := newTestClient()
+	clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", nil)
+	})
+
+	vaults, err := c.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vaultNames(vaults); got != "default" {
+		t.Errorf("vaults = %s, want the context namespace", got)
+	}
+}
+
+func TestListVaultsConfigured(t *testing.T) {
+	c, clientset, _ := newTestClient()
+	c.namespaces = []string{"team-b", "team-a"}
+
+	vaults, err := c.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vaultNames(vaults); got != "team-a,team-b" {
+		t.Errorf("vaults = %s, want team-a,team-b", got)
+	}
+	if n := len(clientset.Actions()); n != 0 {
+		t.Errorf("configured namespaces made %d API calls, want none", n)
+	}
+}
+
+func TestListSecretsMetadataOnly(t *testing.T) {
+	c, clientset, metadataClient := newTestClient()
+
+	pages := []*metav1.List{
+		{
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []runtime.RawExtension{{Object: ptr(secretMeta("prod", "tls"))}, {Object: ptr(secretMeta("prod", "db"))}},
+		},
+		{
+			Items: []runtime.RawExtension{{Object: ptr(secretMeta("prod", "api"))}},
+		},
+	}
+	calls := 0
+	metadataClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "prod" {
+			t.Errorf("listed namespace %q, want prod", action.GetNamespace())
+		}
+		if calls >= len(pages) {
+			t.Fatalf("listed %d pages, want %d", calls+1, len(pages))
+		}
+		calls++
+		return true, pages[calls-1], nil
+	})
+
+	secrets, err := c.ListSecrets(context.Background(), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+		if s.VaultName != "prod" || !s.Enabled || s.CreatedOn == nil || !s.CreatedOn.Equal(created.Time) {
+			t.Errorf("secret %s = %+v", s.Name, s)
+		}
+	}
+	if got := strings.Join(names, ","); got != "api,db,tls" {
+		t.Errorf("secrets = %s, want api,db,tls", got)
+	}
+
+	// Secret data must not be listed through the typed client
+	for _, action := range clientset.Actions() {
+		if action.GetResource().Resource == "secrets" {
+			t.Errorf("typed client was called: %s", action.GetVerb())
+		}
+	}
+}
+
+func TestListSecretsError(t *testing.T) {
+	c, _, metadataClient := newTestClient()
+	metadataClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+	})
+
+	_, err := c.ListSecrets(context.Background(), "prod")
+	if provider.KindOf(err) != provider.ErrPermissionDenied {
+		t.Errorf("error %v is not permission denied", err)
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	c, _, _ := newTestClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "db",
+			Namespace:         "prod",
+			ResourceVersion:   "42",
+			CreationTimestamp: created,
+			Labels:            map[string]string{"team": "platform"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		// The API server sends data base64-encoded; clients receive it decoded
+		Data: map[string][]byte{"username": []byte("app"), "password": []byte("s3cret")},
+	})
+
+	secret, err := c.GetSecret(context.Background(), "prod", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "s3cret" {
+		t.Errorf("Value = %q, want the password field", secret.Value)
+	}
+	if secret.Fields["username"] != "app" || secret.Fields["password"] != "s3cret" || len(secret.Fields) != 2 {
+		t.Errorf("Fields = %v", secret.Fields)
+	}
+	if secret.Version != "42" || secret.Tags["team"] != "platform" || secret.Metadata["type"] != "Opaque" {
+		t.Errorf("secret = %+v", secret)
+	}
+
+	_, err = c.GetSecret(context.Background(), "prod", "missing")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("missing secret error %v is not not found", err)
+	}
+}
+
+func TestPrimaryValue(t *testing.T) {
+	tests := []struct {
+		fields map[string]string
+		want   string
+	}{
+		{nil, ""},
+		{map[string]string{"token": "t"}, "t"},
+		{map[string]string{"value": "v", "password": "p"}, "v"},
+		{map[string]string{"user": "u", "password": "p"}, "p"},
+		{map[string]string{"b": "2", "a": "1"}, "1"},
+	}
+	for _, tt := range tests {
+		if got := primaryValue(tt.fields); got != tt.want {
+			t.Errorf("primaryValue(%v) = %q, want %q", tt.fields, got, tt.want)
+		}
+	}
+}
+
+func TestSetSecretCreate(t *testing.T) {
+	c, clientset, _ := newTestClient()
+
+	_, err := c.SetSecret(context.Background(), "prod", "api", &models.SecretValue{
+		Value: "key",
+		Tags:  map[string]string{"owner": "me"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := clientset.CoreV1().Secrets("prod").Get(context.Background(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != corev1.SecretTypeOpaque || string(s.Data[valueKey]) != "key" || len(s.Data) != 1 || s.Labels["owner"] != "me" {
+		t.Errorf("created secret = %+v", s)
+	}
+}
+
+func TestSetSecretUpdate(t *testing.T) {
+	c, clientset, _ := newTestClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "prod", Labels: map[string]string{"team": "platform"}},
+		Data:       map[string][]byte{"token": []byte("old")},
+	})
+
+	// A single value replaces the only key of an existing single-key Secret
+	_, err := c.SetSecret(context.Background(), "prod", "token", &models.SecretValue{
+		Value: "new",
+		Tags:  map[string]string{"owner": "me"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := clientset.CoreV1().Secrets("prod").Get(context.Background(), "token", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.Data["token"]) != "new" || len(s.Data) != 1 {
+		t.Errorf("Data = %v, want token=new", s.Data)
+	}
+	if s.Labels["team"] != "platform" || s.Labels["owner"] != "me" {
+		t.Errorf("Labels = %v, want the tags merged", s.Labels)
+	}
+
+	// Fields replace the data
+	_, err = c.SetSecret(context.Background(), "prod", "token", &models.SecretValue{
+		Fields: map[string]string{"username": "app", "password": "s3cret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ = clientset.CoreV1().Secrets("prod").Get(context.Background(), "token", metav1.GetOptions{})
+	if string(s.Data["username"]) != "app" || string(s.Data["password"]) != "s3cret" || len(s.Data) != 2 {
+		t.Errorf("Data = %v, want the fields only", s.Data)
+	}
+}
+
+func TestSetSecretConflict(t *testing.T) {
+	c, clientset, _ := newTestClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod", ResourceVersion: "7"},
+		Data:       map[string][]byte{"value": []byte("old")},
+	})
+	clientset.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		updated := action.(k8stesting.UpdateAction).GetObject().(*corev1.Secret)
+		if updated.ResourceVersion != "7" {
+			t.Errorf("ResourceVersion = %q, want the one read", updated.ResourceVersion)
+		}
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "api", nil)
+	})
+
+	_, err := c.SetSecret(context.Background(), "prod", "api", &models.SecretValue{Value: "new"})
+	if !apierrors.IsConflict(err) {
+		t.Errorf("error %v is not a conflict", err)
+	}
+	if !strings.Contains(err.Error(), "failed to set secret") {
+		t.Errorf("error %q is not wrapped", err)
+	}
+}
+
+// vaultNames joins vault names with commas
+func vaultNames(vaults []*models.Vault) string {
+	names := make([]string, len(vaults))
+	for i, v := range vaults {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// ptr returns a pointer to v
+func ptr[T any](v T) *T {
+	return &v
+}
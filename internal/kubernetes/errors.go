@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// classifyError maps Kubernetes API errors onto provider error kinds
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return provider.NewError(provider.ErrNotFound, err)
+	case apierrors.IsForbidden(err):
+		return provider.NewError(provider.ErrPermissionDenied, err)
+	case apierrors.IsUnauthorized(err):
+		return provider.NewError(provider.ErrAuthExpired, err)
+	case apierrors.IsServiceUnavailable(err), apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err), apierrors.IsTimeout(err), apierrors.IsInternalError(err):
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return provider.NewError(provider.ErrUnavailable, err)
+	}
+
+	return err
+}
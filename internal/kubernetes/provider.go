@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Provider implements the provider.Provider interface for Kubernetes Secrets
+// Namespaces are vaults, Secret objects secrets and data keys fields.
+type Provider struct {
+	client *Client
+}
+
+// Schema declares the settings of a Kubernetes instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "kubeconfig"},
+		{Name: "context"},
+		{Name: "namespaces", Type: provider.StringList},
+	},
+}
+
+// NewProvider creates a new Kubernetes provider
+// Configuration options:
+//   - "kubeconfig" (string): kubeconfig file (optional, defaults to $KUBECONFIG or ~/.kube/config)
+//   - "context" (string): kubeconfig context (optional, defaults to the current context)
+//   - "namespaces" ([]string): namespaces listed as vaults (optional, defaults to all the user may list)
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	var kubeconfig, contextName string
+	var namespaces []string
+
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["kubeconfig"].(string); ok {
+			kubeconfig = v
+		}
+		if v, ok := cfg.Settings["context"].(string); ok {
+			contextName = v
+		}
+		if v, ok := cfg.Settings["namespaces"].([]string); ok {
+			namespaces = v
+		}
+	}
+
+	client, err := NewClient(kubeconfig, contextName, namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	return &Provider{
+		client: client,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "kubernetes"
+}
+
+// ListVaults returns the namespaces
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	return p.client.ListVaults(ctx)
+}
+
+// ListSecrets returns the Secret objects of a namespace
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	return p.client.ListSecrets(ctx, vaultName)
+}
+
+// StreamVaults yields namespaces as pages arrive
+func (p *Provider) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return p.client.StreamVaults(ctx)
+}
+
+// StreamSecrets yields the Secret objects of a namespace as pages arrive
+func (p *Provider) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return p.client.StreamSecrets(ctx, vaultName)
+}
+
+// GetSecret retrieves a Secret object with its decoded data
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.client.GetSecret(ctx, vaultName, secretName)
+}
+
+// SetSecret creates or updates a Secret object
+func (p *Provider) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	return p.client.SetSecret(ctx, vaultName, secretName, secret)
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureTags, provider.FeatureWrite:
+		return true
+	default:
+		return false
+	}
+}
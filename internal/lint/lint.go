@@ -0,0 +1,300 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Rule names, as used in the lint.rules config section
+const (
+	// RuleEmpty flags empty values
+	RuleEmpty = "empty"
+	// RuleWhitespace flags values with leading or trailing whitespace or newlines
+	RuleWhitespace = "whitespace"
+	// RuleDuplicate flags identical values stored in more than one secret
+	RuleDuplicate = "duplicate"
+	// RuleWeakPassword flags short or low-variety values of password-like secrets
+	RuleWeakPassword = "weak_password"
+	// RulePrivateKeyContentType flags PEM private keys stored without a content type
+	RulePrivateKeyContentType = "private_key_content_type"
+	// RuleDisabledReferenced flags disabled secrets still used by an alias or favorite
+	RuleDisabledReferenced = "disabled_referenced"
+)
+
+// Rules lists every rule in report order
+var Rules = []string{
+	RuleEmpty,
+	RuleWhitespace,
+	RuleDuplicate,
+	RuleWeakPassword,
+	RulePrivateKeyContentType,
+	RuleDisabledReferenced,
+}
+
+// DefaultMinLength is the minimum length of password-like values
+const DefaultMinLength = 16
+
+// commonPasswords are rejected regardless of length or variety
+var commonPasswords = map[string]bool{
+	"password": true, "passw0rd": true, "123456": true, "12345678": true,
+	"qwerty": true, "letmein": true, "changeme": true, "admin": true,
+	"secret": true, "welcome": true, "default": true, "test": true,
+}
+
+// Issue is a problem found in one secret
+// It never carries the secret value.
+type Issue struct {
+	models.SecretRef `yaml:",inline"`
+	Field            string `json:"field,omitempty" yaml:"field,omitempty"`
+	Rule             string `json:"rule" yaml:"rule"`
+	Message          string `json:"message" yaml:"message"`
+}
+
+// Options configures a Linter
+type Options struct {
+	Rules     map[string]bool // Rule name -> enabled; unlisted rules are enabled
+	MinLength int             // Minimum length of password-like values (0 uses DefaultMinLength)
+}
+
+// Linter checks secret values and collects issues
+// Values are only held as SHA-256 hashes for duplicate detection.
+type Linter struct {
+	enabled   map[string]bool
+	minLength int
+	hashes    map[[sha256.Size]byte][]location
+	issues    []Issue
+}
+
+// location is one place a value was seen
+type location struct {
+	ref   models.SecretRef
+	field string
+}
+
+// New creates a Linter, rejecting unknown rule names
+func New(opts Options) (*Linter, error) {
+	enabled := make(map[string]bool, len(Rules))
+	for _, rule := range Rules {
+		enabled[rule] = true
+	}
+	for rule, on := range opts.Rules {
+		if _, ok := enabled[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule: %s (available: %s)", rule, strings.Join(Rules, ", "))
+		}
+		enabled[rule] = on
+	}
+
+	minLength := opts.MinLength
+	if minLength <= 0 {
+		minLength = DefaultMinLength
+	}
+
+	return &Linter{
+		enabled:   enabled,
+		minLength: minLength,
+		hashes:    make(map[[sha256.Size]byte][]location),
+	}, nil
+}
+
+// CheckValue runs the per-value rules against a secret
+// Multi-field secrets are checked field by field.
+func (l *Linter) CheckValue(ref models.SecretRef, secret *models.SecretValue) {
+	if len(secret.Fields) == 0 {
+		l.checkString(ref, "", secret.Value, secret)
+		return
+	}
+
+	fields := make([]string, 0, len(secret.Fields))
+	for name := range secret.Fields {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	for _, name := range fields {
+		l.checkString(ref, name, secret.Fields[name], secret)
+	}
+}
+
+// CheckDisabled flags a disabled secret that is still referenced
+// referencedBy describes the references, e.g. "alias prod-db".
+func (l *Linter) CheckDisabled(ref models.SecretRef, referencedBy []string) {
+	if len(referencedBy) == 0 {
+		return
+	}
+	l.add(ref, "", RuleDisabledReferenced, "disabled but referenced by %s", strings.Join(referencedBy, ", "))
+}
+
+// Issues returns every issue found so far, including duplicates, ordered by
+// secret reference, field and rule
+func (l *Linter) Issues() []Issue {
+	issues := append([]Issue(nil), l.issues...)
+
+	if l.enabled[RuleDuplicate] {
+		for _, locations := range l.hashes {
+			if len(locations) < 2 {
+				continue
+			}
+			for i, loc := range locations {
+				var others []string
+				for j, other := range locations {
+					if i != j {
+						others = append(others, other.String())
+					}
+				}
+				sort.Strings(others)
+				issues = append(issues, Issue{
+					SecretRef: loc.ref,
+					Field:     loc.field,
+					Rule:      RuleDuplicate,
+					Message:   "same value as " + strings.Join(others, ", "),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.SecretRef != b.SecretRef {
+			return a.SecretRef.String() < b.SecretRef.String()
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return ruleIndex(a.Rule) < ruleIndex(b.Rule)
+	})
+	return issues
+}
+
+// checkString runs the per-value rules against one value
+func (l *Linter) checkString(ref models.SecretRef, field, value string, secret *models.SecretValue) {
+	if value == "" {
+		l.add(ref, field, RuleEmpty, "value is empty")
+		return
+	}
+
+	if l.enabled[RuleWhitespace] {
+		if trimmed := strings.TrimSpace(value); trimmed != value {
+			switch {
+			case strings.HasSuffix(value, "\n") || strings.HasSuffix(value, "\r"):
+				l.add(ref, field, RuleWhitespace, "value ends with a newline")
+			case value[0] != trimmed[0]:
+				l.add(ref, field, RuleWhitespace, "value has leading whitespace")
+			default:
+				l.add(ref, field, RuleWhitespace, "value has trailing whitespace")
+			}
+		}
+	}
+
+	hash := sha256.Sum256([]byte(value))
+	l.hashes[hash] = append(l.hashes[hash], location{ref: ref, field: field})
+
+	if isPasswordLike(ref.Secret) || isPasswordLike(field) {
+		if reason := weakness(value, l.minLength); reason != "" {
+			l.add(ref, field, RuleWeakPassword, "weak password: %s", reason)
+		}
+	}
+
+	if isPrivateKey(value) && contentType(secret) == "" {
+		l.add(ref, field, RulePrivateKeyContentType, "private key stored without a content type")
+	}
+}
+
+// add records an issue if its rule is enabled
+func (l *Linter) add(ref models.SecretRef, field, rule, format string, args ...interface{}) {
+	if !l.enabled[rule] {
+		return
+	}
+	l.issues = append(l.issues, Issue{
+		SecretRef: ref,
+		Field:     field,
+		Rule:      rule,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// String returns the location as ref or ref#field
+func (loc location) String() string {
+	if loc.field == "" {
+		return loc.ref.String()
+	}
+	return loc.ref.String() + "#" + loc.field
+}
+
+// isPasswordLike reports whether a secret or field name suggests a password
+func isPasswordLike(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range []string{"password", "passwd", "passphrase", "pwd"} {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// weakness explains why a password is weak, or returns "" if it is not
+func weakness(value string, minLength int) string {
+	if commonPasswords[strings.ToLower(value)] {
+		return "commonly used password"
+	}
+	if n := len([]rune(value)); n < minLength {
+		return fmt.Sprintf("%d characters (minimum %d)", n, minLength)
+	}
+
+	var lower, upper, digit, other bool
+	for _, r := range value {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	if classes < 2 {
+		return "only one character class"
+	}
+	return ""
+}
+
+// isPrivateKey reports whether a value looks like a PEM-encoded private key
+func isPrivateKey(value string) bool {
+	return strings.Contains(value, "-----BEGIN") && strings.Contains(value, "PRIVATE KEY-----")
+}
+
+// contentType returns the secret's content type, falling back to a
+// content_type entry in provider metadata (e.g. KV v2 custom_metadata)
+func contentType(secret *models.SecretValue) string {
+	if secret.ContentType != "" {
+		return secret.ContentType
+	}
+	for key, value := range secret.Metadata {
+		switch strings.ToLower(key) {
+		case "content_type", "contenttype", "content-type":
+			return value
+		}
+	}
+	return ""
+}
+
+// ruleIndex orders rules as listed in Rules
+func ruleIndex(rule string) int {
+	for i, r := range Rules {
+		if r == rule {
+			return i
+		}
+	}
+	return len(Rules)
+}
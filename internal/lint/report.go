@@ -0,0 +1,32 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ylchen07/smart-keyvault/internal/output"
+)
+
+// FormatIssues renders issues as a table or JSON
+func FormatIssues(issues []Issue, format string) (string, error) {
+	switch format {
+	case "table":
+		rows := [][]string{{"RULE", "SECRET", "FIELD", "MESSAGE"}}
+		for _, issue := range issues {
+			field := issue.Field
+			if field == "" {
+				field = "-"
+			}
+			rows = append(rows, []string{issue.Rule, issue.SecretRef.String(), field, issue.Message})
+		}
+		return output.WriteTable(rows), nil
+	case "json":
+		data, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s (use table or json)", format)
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// demoFixture is served when an instance has no fixture file
+//
+//go:embed demo.yaml
+var demoFixture []byte
+
+// Fixture is the YAML (or JSON) document backing a memory instance
+type Fixture struct {
+	Vaults []FixtureVault `yaml:"vaults" json:"vaults"`
+}
+
+// FixtureVault is one vault of a fixture
+type FixtureVault struct {
+	Name     string            `yaml:"name" json:"name"`
+	Metadata map[string]string `yaml:"metadata" json:"metadata"`
+	Error    string            `yaml:"error" json:"error"` // Fail every call on this vault with this error kind (e.g. permission_denied)
+	Secrets  []FixtureSecret   `yaml:"secrets" json:"secrets"`
+}
+
+// FixtureSecret is one secret of a fixture vault
+// A secret has either a single Value/Fields or a list of Versions, oldest
+// first; the last version is current.
+type FixtureSecret struct {
+	Name        string            `yaml:"name" json:"name"`
+	Enabled     *bool             `yaml:"enabled" json:"enabled"` // Defaults to true
+	ContentType string            `yaml:"content_type" json:"content_type"`
+	Tags        map[string]string `yaml:"tags" json:"tags"`
+	Metadata    map[string]string `yaml:"metadata" json:"metadata"`
+	ExpiresOn   *time.Time        `yaml:"expires_on" json:"expires_on"`
+	Error       string            `yaml:"error" json:"error"` // Fail reads of this secret with this error kind
+
+	Value     string            `yaml:"value" json:"value"`
+	Fields    map[string]string `yaml:"fields" json:"fields"`
+	CreatedOn *time.Time        `yaml:"created_on" json:"created_on"`
+
+	Versions []FixtureVersion `yaml:"versions" json:"versions"`
+}
+
+// FixtureVersion is one version of a fixture secret
+type FixtureVersion struct {
+	Version   string            `yaml:"version" json:"version"` // Defaults to the 1-based position
+	Value     string            `yaml:"value" json:"value"`
+	Fields    map[string]string `yaml:"fields" json:"fields"`
+	CreatedOn *time.Time        `yaml:"created_on" json:"created_on"`
+}
+
+// LoadFixture reads a fixture file; an empty path returns the built-in demo data
+func LoadFixture(path string) (*Fixture, error) {
+	data := demoFixture
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+	}
+
+	// JSON is valid YAML, so one decoder handles both
+	var f Fixture
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if err := f.normalize(); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// normalize validates the fixture and fills in defaults
+func (f *Fixture) normalize() error {
+	vaults := make(map[string]bool)
+	for i := range f.Vaults {
+		v := &f.Vaults[i]
+		if v.Name == "" {
+			return fmt.Errorf("vault at index %d has no name", i)
+		}
+		if vaults[v.Name] {
+			return fmt.Errorf("duplicate vault '%s'", v.Name)
+		}
+		vaults[v.Name] = true
+		if err := checkKind(v.Error); err != nil {
+			return fmt.Errorf("vault '%s': %w", v.Name, err)
+		}
+
+		secrets := make(map[string]bool)
+		for j := range v.Secrets {
+			s := &v.Secrets[j]
+			if s.Name == "" {
+				return fmt.Errorf("vault '%s': secret at index %d has no name", v.Name, j)
+			}
+			if secrets[s.Name] {
+				return fmt.Errorf("vault '%s': duplicate secret '%s'", v.Name, s.Name)
+			}
+			secrets[s.Name] = true
+			if err := checkKind(s.Error); err != nil {
+				return fmt.Errorf("secret '%s/%s': %w", v.Name, s.Name, err)
+			}
+
+			// A single value is shorthand for one version
+			if len(s.Versions) == 0 {
+				s.Versions = []FixtureVersion{{Value: s.Value, Fields: s.Fields, CreatedOn: s.CreatedOn}}
+			}
+			for k := range s.Versions {
+				if s.Versions[k].Version == "" {
+					s.Versions[k].Version = strconv.Itoa(k + 1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkKind validates an injected error kind name
+func checkKind(name string) error {
+	if name != "" && provider.KindByName(name) == nil {
+		return fmt.Errorf("unknown error kind '%s' (use not_found, permission_denied, auth_expired, sealed or unavailable)", name)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Provider implements the provider.Provider interface over an in-memory
+// fixture, for tests and offline demos. It implements every optional
+// provider interface; writes change the in-memory copy only.
+type Provider struct {
+	mu      sync.RWMutex
+	fixture *Fixture
+}
+
+// Schema declares the settings of a memory instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "fixture"},
+	},
+}
+
+// NewProvider creates a new memory provider
+// Configuration options:
+//   - "fixture" (string): path to a YAML or JSON fixture (optional, defaults to built-in demo data)
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	var path string
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["fixture"].(string); ok {
+			path = v
+		}
+	}
+
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{fixture: fixture}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "memory"
+}
+
+// ListVaults returns the fixture's vaults sorted by name
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	return collect(p.StreamVaults(ctx), func(a, b *models.Vault) bool { return a.Name < b.Name })
+}
+
+// StreamVaults yields the fixture's vaults in fixture order
+func (p *Provider) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return func(yield func(*models.Vault, error) bool) {
+		p.mu.RLock()
+		vaults := make([]*models.Vault, 0, len(p.fixture.Vaults))
+		for _, v := range p.fixture.Vaults {
+			vaults = append(vaults, &models.Vault{Name: v.Name, Provider: "memory", Metadata: maps.Clone(v.Metadata)})
+		}
+		p.mu.RUnlock()
+
+		for _, v := range vaults {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ListSecrets returns the secrets of a vault sorted by name
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	secrets, err := collect(p.StreamSecrets(ctx, vaultName), func(a, b *models.Secret) bool { return a.Name < b.Name })
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// StreamSecrets yields the secrets of a vault in fixture order
+func (p *Provider) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		p.mu.RLock()
+		vault, err := p.vault(vaultName)
+		var secrets []*models.Secret
+		if err == nil {
+			for i := range vault.Secrets {
+				secrets = append(secrets, secretInfo(vaultName, &vault.Secrets[i]))
+			}
+		}
+		p.mu.RUnlock()
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, s := range secrets {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetSecret returns the current version of a secret
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	return p.GetSecretVersion(ctx, vaultName, secretName, "")
+}
+
+// GetSecretVersion returns a specific version of a secret ("" is current)
+func (p *Provider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	secret, err := p.readable(vaultName, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	current := &secret.Versions[len(secret.Versions)-1]
+	selected := current
+	if version != "" {
+		selected = nil
+		for i := range secret.Versions {
+			if secret.Versions[i].Version == version {
+				selected = &secret.Versions[i]
+				break
+			}
+		}
+		if selected == nil {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("secret '%s' has no version '%s'", secretName, version))
+		}
+	}
+
+	info := secretInfo(vaultName, secret)
+	return &models.SecretValue{
+		Name:        secretName,
+		Value:       primaryValue(selected),
+		VaultName:   vaultName,
+		Provider:    "memory",
+		Fields:      maps.Clone(selected.Fields),
+		Version:     selected.Version,
+		ContentType: secret.ContentType,
+		CreatedOn:   info.CreatedOn,
+		UpdatedOn:   selected.CreatedOn,
+		ExpiresOn:   secret.ExpiresOn,
+		Tags:        maps.Clone(secret.Tags),
+		Metadata:    maps.Clone(secret.Metadata),
+	}, nil
+}
+
+// GetSecretMetadata returns a secret with its timestamps without reading its value
+func (p *Provider) GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	vault, err := p.vault(vaultName)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := findSecret(vault, secretName)
+	if err != nil {
+		return nil, err
+	}
+	return secretInfo(vaultName, secret), nil
+}
+
+// SetSecret adds a new current version to a secret, creating the secret and
+// its vault if needed. Changes are not written back to the fixture file.
+func (p *Provider) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	if vaultName == "" || secretName == "" {
+		return nil, fmt.Errorf("vault and secret names are required")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	vault, err := p.vault(vaultName)
+	if provider.KindOf(err) == provider.ErrNotFound {
+		p.fixture.Vaults = append(p.fixture.Vaults, FixtureVault{Name: vaultName})
+		vault, err = &p.fixture.Vaults[len(p.fixture.Vaults)-1], nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set secret: %w", err)
+	}
+
+	target, err := findSecret(vault, secretName)
+	if err != nil {
+		vault.Secrets = append(vault.Secrets, FixtureSecret{Name: secretName})
+		target = &vault.Secrets[len(vault.Secrets)-1]
+	} else if target.Error != "" {
+		return nil, fmt.Errorf("failed to set secret: %w", injectedError(target.Error, "secret "+vaultName+"/"+secretName))
+	}
+
+	now := time.Now().UTC()
+	target.Versions = append(target.Versions, FixtureVersion{
+		Version:   strconv.Itoa(len(target.Versions) + 1),
+		Value:     secret.Value,
+		Fields:    maps.Clone(secret.Fields),
+		CreatedOn: &now,
+	})
+	if secret.ContentType != "" {
+		target.ContentType = secret.ContentType
+	}
+	if secret.Tags != nil {
+		target.Tags = maps.Clone(secret.Tags)
+	}
+	return secretInfo(vaultName, target), nil
+}
+
+// SupportsFeature reports every feature as supported
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureVersioning, provider.FeatureMetadata, provider.FeatureTags, provider.FeatureWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// vault returns a fixture vault, failing with its injected error if any
+func (p *Provider) vault(name string) (*FixtureVault, error) {
+	for i := range p.fixture.Vaults {
+		v := &p.fixture.Vaults[i]
+		if v.Name != name {
+			continue
+		}
+		if v.Error != "" {
+			return nil, injectedError(v.Error, "vault "+name)
+		}
+		return v, nil
+	}
+	return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("vault '%s' not found", name))
+}
+
+// readable returns a secret whose value may be read
+func (p *Provider) readable(vaultName, secretName string) (*FixtureSecret, error) {
+	vault, err := p.vault(vaultName)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := findSecret(vault, secretName)
+	if err != nil {
+		return nil, err
+	}
+	if secret.Error != "" {
+		return nil, injectedError(secret.Error, "secret "+vaultName+"/"+secretName)
+	}
+	if secret.Enabled != nil && !*secret.Enabled {
+		return nil, provider.NewError(provider.ErrPermissionDenied, fmt.Errorf("secret '%s' is disabled", secretName))
+	}
+	return secret, nil
+}
+
+// findSecret looks up a secret in a vault
+func findSecret(vault *FixtureVault, name string) (*FixtureSecret, error) {
+	for i := range vault.Secrets {
+		if vault.Secrets[i].Name == name {
+			return &vault.Secrets[i], nil
+		}
+	}
+	return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("secret not found"))
+}
+
+// secretInfo describes a secret without its value
+// CreatedOn is when the first version was written, UpdatedOn the current one.
+func secretInfo(vaultName string, s *FixtureSecret) *models.Secret {
+	return &models.Secret{
+		Name:      s.Name,
+		VaultName: vaultName,
+		Provider:  "memory",
+		Enabled:   s.Enabled == nil || *s.Enabled,
+		CreatedOn: s.Versions[0].CreatedOn,
+		UpdatedOn: s.Versions[len(s.Versions)-1].CreatedOn,
+		ExpiresOn: s.ExpiresOn,
+	}
+}
+
+// primaryValue returns a version's value, or for multi-field versions the
+// "value" field, then "password", then the first field by name (as for KV v2)
+func primaryValue(v *FixtureVersion) string {
+	if v.Value != "" || len(v.Fields) == 0 {
+		return v.Value
+	}
+	if value, ok := v.Fields["value"]; ok {
+		return value
+	}
+	if value, ok := v.Fields["password"]; ok {
+		return value
+	}
+	return v.Fields[slices.Sorted(maps.Keys(v.Fields))[0]]
+}
+
+// injectedError returns the error a fixture asks for
+func injectedError(kind, what string) error {
+	return provider.NewError(provider.KindByName(kind), fmt.Errorf("%s: injected %s error", what, kind))
+}
+
+// collect drains a stream and sorts the result
+func collect[T any](seq iter.Seq2[T, error], less func(a, b T) bool) ([]T, error) {
+	items := make([]T, 0)
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+	return items, nil
+}
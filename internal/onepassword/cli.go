@@ -0,0 +1,86 @@
+package onepassword
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// cli runs the op binary for one instance
+// Tokens are passed through the environment so they never show up in the
+// process list.
+type cli struct {
+	bin     string
+	account string   // --account for a signed-in desktop or CLI account
+	env     []string // OP_SERVICE_ACCOUNT_TOKEN / OP_CONNECT_* for this instance
+}
+
+// run executes op with JSON output and decodes stdout into out
+func (c *cli) run(ctx context.Context, out any, args ...string) error {
+	args = append(args, "--format", "json")
+	if c.account != "" {
+		args = append(args, "--account", c.account)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.bin, args...)
+	cmd.Env = append(os.Environ(), c.env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		clear(stdout.Bytes())
+		if errors.Is(err, exec.ErrNotFound) {
+			return provider.NewError(provider.ErrUnavailable, fmt.Errorf("%s not found in PATH", c.bin))
+		}
+		return classify(strings.TrimSpace(stderr.String()), err)
+	}
+
+	defer clear(stdout.Bytes())
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("failed to parse %s output: %w", c.bin, err)
+	}
+	return nil
+}
+
+// classify maps op's diagnostics to provider error kinds
+func classify(stderr string, err error) error {
+	// op prefixes errors with "[ERROR] <date> <time> "
+	msg := err.Error()
+	if stderr != "" {
+		lines := strings.Split(stderr, "\n")
+		msg = lines[len(lines)-1]
+		if rest, ok := strings.CutPrefix(msg, "[ERROR] "); ok {
+			if fields := strings.SplitN(rest, " ", 3); len(fields) == 3 {
+				msg = fields[2]
+			}
+		}
+	}
+	wrapped := fmt.Errorf("op: %s", msg)
+
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "not currently signed in"), strings.Contains(lower, "session expired"),
+		strings.Contains(lower, "account is not signed in"), strings.Contains(lower, "authorization prompt dismissed"),
+		strings.Contains(lower, "invalid session token"), strings.Contains(lower, "(401)"):
+		return provider.NewError(provider.ErrAuthExpired, wrapped)
+	case strings.Contains(lower, "isn't an item"), strings.Contains(lower, "isn't a vault"),
+		strings.Contains(lower, "not found"), strings.Contains(lower, "(404)"):
+		return provider.NewError(provider.ErrNotFound, wrapped)
+	case strings.Contains(lower, "forbidden"), strings.Contains(lower, "(403)"),
+		strings.Contains(lower, "does not have permission"), strings.Contains(lower, "doesn't have permission"):
+		return provider.NewError(provider.ErrPermissionDenied, wrapped)
+	case strings.Contains(lower, "connection refused"), strings.Contains(lower, "no such host"),
+		strings.Contains(lower, "timeout"):
+		return provider.NewError(provider.ErrUnavailable, wrapped)
+	default:
+		return wrapped
+	}
+}
@@ -0,0 +1,101 @@
+package onepassword
+
+import (
+	"strings"
+	"time"
+)
+
+// vault is a vault as printed by `op vault list`
+type vault struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// item is an item as printed by `op item list` and `op item get`; fields
+// and URLs are only present in `op item get`
+type item struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Version   int       `json:"version"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Fields    []field   `json:"fields"`
+	URLs      []struct {
+		Href    string `json:"href"`
+		Primary bool   `json:"primary"`
+	} `json:"urls"`
+}
+
+// field is one field of an item
+type field struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`    // STRING, CONCEALED, OTP, ...
+	Purpose string `json:"purpose"` // USERNAME, PASSWORD or NOTES for built-in fields
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Section *struct {
+		Label string `json:"label"`
+	} `json:"section"`
+}
+
+// fieldMap returns an item's non-empty fields keyed by label
+// A label repeated in another section is prefixed with that section's label,
+// and the primary URL is added as "url".
+func (it *item) fieldMap() map[string]string {
+	fields := make(map[string]string, len(it.Fields)+1)
+	for _, f := range it.Fields {
+		if f.Value == "" {
+			continue
+		}
+		key := f.Label
+		if key == "" {
+			key = f.ID
+		}
+		if _, taken := fields[key]; taken && f.Section != nil && f.Section.Label != "" {
+			key = f.Section.Label + "." + key
+		}
+		fields[key] = f.Value
+	}
+
+	for _, u := range it.URLs {
+		if _, taken := fields["url"]; u.Primary && !taken {
+			fields["url"] = u.Href
+		}
+	}
+	return fields
+}
+
+// primaryValue returns the item's password, else an API credential, else
+// its first concealed field, else its notes
+func (it *item) primaryValue() string {
+	var concealed, notes string
+	for _, f := range it.Fields {
+		switch {
+		case f.Value == "":
+		case f.Purpose == "PASSWORD", f.ID == "credential":
+			return f.Value
+		case f.Type == "CONCEALED" && concealed == "":
+			concealed = f.Value
+		case f.Purpose == "NOTES":
+			notes = f.Value
+		}
+	}
+	if concealed != "" {
+		return concealed
+	}
+	return notes
+}
+
+// metadata returns an item's category, tags and ID
+func (it *item) metadata() map[string]string {
+	m := map[string]string{"id": it.ID}
+	if it.Category != "" {
+		m["category"] = strings.ToLower(it.Category)
+	}
+	if len(it.Tags) > 0 {
+		m["tags"] = strings.Join(it.Tags, ",")
+	}
+	return m
+}
@@ -0,0 +1,161 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Provider implements the provider.Provider interface for 1Password through
+// the op CLI: vaults are vaults, items secrets and item fields fields
+type Provider struct {
+	op *cli
+}
+
+// Schema declares the settings of a 1Password instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "account"},
+		{Name: "service_account_token", Secret: true},
+		{Name: "connect_host"},
+		{Name: "connect_token", Secret: true},
+		{Name: "binary", Default: "op"},
+	},
+	Validate: func(settings map[string]interface{}) error {
+		if (settings["connect_host"] == "") != (settings["connect_token"] == "") {
+			return fmt.Errorf("must set connect_host and connect_token together")
+		}
+		return nil
+	},
+}
+
+// NewProvider creates a new 1Password provider
+// Configuration options:
+//   - "account" (string): account shorthand, sign-in address or ID (optional, defaults to op's default account)
+//   - "service_account_token" (string): service account token (optional)
+//   - "connect_host" (string): 1Password Connect server URL (optional, requires connect_token)
+//   - "connect_token" (string): 1Password Connect token (optional)
+//   - "binary" (string): op binary (optional, defaults to "op")
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	op := &cli{bin: "op"}
+	var connectHost, connectToken string
+
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["account"].(string); ok {
+			op.account = v
+		}
+		if v, ok := cfg.Settings["service_account_token"].(string); ok && v != "" {
+			op.env = append(op.env, "OP_SERVICE_ACCOUNT_TOKEN="+v)
+		}
+		if v, ok := cfg.Settings["connect_host"].(string); ok {
+			connectHost = v
+		}
+		if v, ok := cfg.Settings["connect_token"].(string); ok {
+			connectToken = v
+		}
+		if v, ok := cfg.Settings["binary"].(string); ok && v != "" {
+			op.bin = v
+		}
+	}
+
+	if (connectHost == "") != (connectToken == "") {
+		return nil, fmt.Errorf("connect_host and connect_token must be set together for the 1Password provider")
+	}
+	if connectHost != "" {
+		op.env = append(op.env, "OP_CONNECT_HOST="+connectHost, "OP_CONNECT_TOKEN="+connectToken)
+	}
+
+	return &Provider{op: op}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "onepassword"
+}
+
+// ListVaults returns the vaults the account can access sorted by name
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	var list []vault
+	if err := p.op.run(ctx, &list, "vault", "list"); err != nil {
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+
+	vaults := make([]*models.Vault, 0, len(list))
+	for _, v := range list {
+		vaults = append(vaults, &models.Vault{
+			Name:     v.Name,
+			Provider: "onepassword",
+			Metadata: map[string]string{"id": v.ID},
+		})
+	}
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the items of a vault sorted by title
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	var list []item
+	if err := p.op.run(ctx, &list, "item", "list", "--vault", vaultName); err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	secrets := make([]*models.Secret, 0, len(list))
+	for _, it := range list {
+		secrets = append(secrets, &models.Secret{
+			Name:      it.Title,
+			VaultName: vaultName,
+			Provider:  "onepassword",
+			Enabled:   true,
+			CreatedOn: timePtr(it.CreatedAt),
+			UpdatedOn: timePtr(it.UpdatedAt),
+		})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// GetSecret retrieves an item by title or ID; its fields become fields
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	var it item
+	if err := p.op.run(ctx, &it, "item", "get", secretName, "--vault", vaultName); err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	value := &models.SecretValue{
+		Name:      secretName,
+		Value:     it.primaryValue(),
+		VaultName: vaultName,
+		Provider:  "onepassword",
+		Fields:    it.fieldMap(),
+		CreatedOn: timePtr(it.CreatedAt),
+		UpdatedOn: timePtr(it.UpdatedAt),
+		Metadata:  it.metadata(),
+	}
+	if it.Version > 0 {
+		value.Version = strconv.Itoa(it.Version)
+	}
+	return value, nil
+}
+
+// SupportsFeature checks if the provider supports a specific feature
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	switch feature {
+	case provider.FeatureMetadata:
+		return true
+	default:
+		return false
+	}
+}
+
+// timePtr returns nil for a zero time
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
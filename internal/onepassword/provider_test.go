@@ -0,0 +1,250 @@
+package onepassword
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/smart-keyvault/internal/fakecli"
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// fakeOp installs a fake op on PATH answering each argument list with its
+// response, recording the instance's environment variables
+func fakeOp(t *testing.T, responses map[string]fakecli.Response) func() []fakecli.Call {
+	t.Helper()
+	return fakecli.Install(t, "op", []string{"OP_SERVICE_ACCOUNT_TOKEN", "OP_CONNECT_HOST", "OP_CONNECT_TOKEN"}, responses)
+}
+
+// newTestProvider creates a provider with the given settings
+func newTestProvider(t *testing.T, settings map[string]interface{}) *Provider {
+	t.Helper()
+	p, err := NewProvider(&provider.Config{Name: "onepassword", Instance: "test", Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*Provider)
+}
+
+const itemJSON = `{
+  "id": "abc123",
+  "title": "db",
+  "category": "LOGIN",
+  "version": 3,
+  "tags": ["prod", "postgres"],
+  "created_at": "2024-01-01T00:00:00Z",
+  "updated_at": "2024-06-01T12:00:00Z",
+  "fields": [
+    {"id": "username", "type": "STRING", "purpose": "USERNAME", "label": "username", "value": "app"},
+    {"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password", "value": "s3cret"},
+    {"id": "notesPlain", "type": "STRING", "purpose": "NOTES", "label": "notesPlain", "value": ""},
+    {"id": "h1", "type": "STRING", "label": "host", "value": "db.internal", "section": {"label": "primary"}},
+    {"id": "h2", "type": "STRING", "label": "host", "value": "replica.internal", "section": {"label": "replica"}}
+  ],
+  "urls": [{"href": "https://admin.example.com", "primary": true}]
+}`
+
+func TestListVaults(t *testing.T) {
+	calls := fakeOp(t, map[string]fakecli.Response{
+		"vault list --format json": {Stdout: `[{"id":"v2","name":"Shared"},{"id":"v1","name":"Private"}]`},
+	})
+	p := newTestProvider(t, nil)
+
+	vaults, err := p.ListVaults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vaults) != 2 || vaults[0].Name != "Private" || vaults[0].Metadata["id"] != "v1" || vaults[1].Name != "Shared" {
+		t.Errorf("vaults = %v", vaults)
+	}
+	if n := len(calls()); n != 1 {
+		t.Errorf("made %d calls, want 1", n)
+	}
+}
+
+func TestListSecrets(t *testing.T) {
+	fakeOp(t, map[string]fakecli.Response{
+		"item list --vault Private --format json": {Stdout: `[
+			{"id":"i2","title":"web","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-06-01T12:00:00Z"},
+			{"id":"i1","title":"db"}
+		]`},
+	})
+	p := newTestProvider(t, nil)
+
+	secrets, err := p.ListSecrets(context.Background(), "Private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 || secrets[0].Name != "db" || secrets[1].Name != "web" {
+		t.Fatalf("secrets = %v", secrets)
+	}
+	if secrets[0].CreatedOn != nil || secrets[1].UpdatedOn == nil || secrets[1].VaultName != "Private" {
+		t.Errorf("secrets = %+v, %+v", secrets[0], secrets[1])
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	fakeOp(t, map[string]fakecli.Response{
+		"item get db --vault Private --format json": {Stdout: itemJSON},
+	})
+	p := newTestProvider(t, nil)
+
+	secret, err := p.GetSecret(context.Background(), "Private", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Value != "s3cret" || secret.Version != "3" || secret.UpdatedOn == nil {
+		t.Errorf("secret = %+v", secret)
+	}
+
+	want := map[string]string{
+		"username":     "app",
+		"password":     "s3cret",
+		"host":         "db.internal",
+		"replica.host": "replica.internal",
+		"url":          "https://admin.example.com",
+	}
+	if len(secret.Fields) != len(want) {
+		t.Errorf("Fields = %v, want %v", secret.Fields, want)
+	}
+	for key, value := range want {
+		if secret.Fields[key] != value {
+			t.Errorf("Fields[%s] = %q, want %q", key, secret.Fields[key], value)
+		}
+	}
+
+	if secret.Metadata["id"] != "abc123" || secret.Metadata["category"] != "login" || secret.Metadata["tags"] != "prod,postgres" {
+		t.Errorf("Metadata = %v", secret.Metadata)
+	}
+}
+
+func TestPrimaryValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []field
+		want   string
+	}{
+		{"password", []field{{Type: "CONCEALED", Label: "pin", Value: "1"}, {Purpose: "PASSWORD", Value: "pw"}}, "pw"},
+		{"api credential", []field{{ID: "credential", Type: "CONCEALED", Value: "key"}}, "key"},
+		{"first concealed", []field{{Purpose: "NOTES", Value: "n"}, {Type: "CONCEALED", Value: "c1"}, {Type: "CONCEALED", Value: "c2"}}, "c1"},
+		{"notes", []field{{Purpose: "NOTES", Value: "n"}}, "n"},
+		{"empty password skipped", []field{{Purpose: "PASSWORD"}, {Purpose: "NOTES", Value: "n"}}, "n"},
+		{"nothing", nil, ""},
+	}
+	for _, tt := range tests {
+		it := &item{Fields: tt.fields}
+		if got := it.primaryValue(); got != tt.want {
+			t.Errorf("%s: primaryValue() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccountAndEnv(t *testing.T) {
+	calls := fakeOp(t, map[string]fakecli.Response{
+		"vault list --format json --account team.1password.com": {Stdout: `[]`},
+	})
+	p := newTestProvider(t, map[string]interface{}{
+		"account":               "team.1password.com",
+		"service_account_token": "ops_token",
+		"connect_host":          "http://connect:8080",
+		"connect_token":         "connect_token",
+	})
+
+	if _, err := p.ListVaults(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := calls()
+	if len(got) != 1 {
+		t.Fatalf("calls = %v", got)
+	}
+	want := "OP_SERVICE_ACCOUNT_TOKEN=ops_token OP_CONNECT_HOST=http://connect:8080 OP_CONNECT_TOKEN=connect_token"
+	if got[0].Env != want {
+		t.Errorf("op saw %q, want %q", got[0].Env, want)
+	}
+	// Tokens must not be visible in the process list
+	if strings.Contains(got[0].Args, "token") {
+		t.Errorf("token passed as an argument: %s", got[0].Args)
+	}
+}
+
+func TestConnectSettingsTogether(t *testing.T) {
+	_, err := NewProvider(&provider.Config{Settings: map[string]interface{}{"connect_host": "http://connect:8080"}})
+	if err == nil {
+		t.Error("connect_host without connect_token was accepted")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	fakeOp(t, map[string]fakecli.Response{
+		"item get expired --vault Private --format json": {
+			Stderr: "[ERROR] 2024/06/01 12:00:00 You are not currently signed in. Please run `op signin --help` for instructions",
+			Code:   1,
+		},
+		"item get missing --vault Private --format json": {
+			Stderr: `[ERROR] 2024/06/01 12:00:00 "missing" isn't an item in the "Private" vault. Specify the item with its UUID, name, or domain.`,
+			Code:   1,
+		},
+		"item get garbled --vault Private --format json": {Stdout: "not json"},
+	})
+	p := newTestProvider(t, nil)
+
+	_, err := p.GetSecret(context.Background(), "Private", "expired")
+	if provider.KindOf(err) != provider.ErrAuthExpired {
+		t.Errorf("expired session error %v is not auth expired", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "[ERROR]") {
+		t.Errorf("error %q keeps op's log prefix", err)
+	}
+
+	_, err = p.GetSecret(context.Background(), "Private", "missing")
+	if provider.KindOf(err) != provider.ErrNotFound {
+		t.Errorf("missing item error %v is not not found", err)
+	}
+
+	_, err = p.GetSecret(context.Background(), "Private", "garbled")
+	if err == nil || !strings.Contains(err.Error(), "failed to parse op output") {
+		t.Errorf("garbled output error = %v", err)
+	}
+}
+
+func TestMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	p := newTestProvider(t, nil)
+
+	_, err := p.ListVaults(context.Background())
+	if provider.KindOf(err) != provider.ErrUnavailable {
+		t.Errorf("missing binary error %v is not unavailable", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		stderr  string
+		kind    error
+		message string
+	}{
+		{"[ERROR] 2024/06/01 12:00:00 You are not currently signed in.", provider.ErrAuthExpired, "op: You are not currently signed in."},
+		{"[ERROR] 2024/06/01 12:00:00 session expired, sign in to create a new session", provider.ErrAuthExpired, ""},
+		{"[ERROR] 2024/06/01 12:00:00 authorization prompt dismissed, please try again", provider.ErrAuthExpired, ""},
+		{"[ERROR] 2024/06/01 12:00:00 (401) Unauthorized: Invalid token", provider.ErrAuthExpired, ""},
+		{`[ERROR] 2024/06/01 12:00:00 "x" isn't a vault in this account.`, provider.ErrNotFound, ""},
+		{"[ERROR] 2024/06/01 12:00:00 (404) Not Found", provider.ErrNotFound, ""},
+		{"[ERROR] 2024/06/01 12:00:00 (403) Forbidden", provider.ErrPermissionDenied, ""},
+		{"[ERROR] 2024/06/01 12:00:00 Service account does not have permission to access vault", provider.ErrPermissionDenied, ""},
+		{"[ERROR] 2024/06/01 12:00:00 dial tcp: connect: connection refused", provider.ErrUnavailable, ""},
+		{"warning\n[ERROR] 2024/06/01 12:00:00 something else", nil, "op: something else"},
+		{"", nil, "op: exit status 1"},
+	}
+	for _, tt := range tests {
+		err := classify(tt.stderr, exitErr)
+		if provider.KindOf(err) != tt.kind {
+			t.Errorf("classify(%q) has kind %v, want %v", tt.stderr, provider.KindOf(err), tt.kind)
+		}
+		if tt.message != "" && err.Error() != tt.message {
+			t.Errorf("classify(%q) = %q, want %q", tt.stderr, err, tt.message)
+		}
+	}
+}
@@ -5,12 +5,18 @@ import (
 )
 
 // GetFormatter returns the appropriate formatter based on format type
-func GetFormatter(format Format) (Formatter, error) {
+func GetFormatter(format Format, opts Options) (Formatter, error) {
 	switch format {
 	case FormatPlain:
 		return NewPlainFormatter(), nil
 	case FormatJSON:
 		return NewJSONFormatter(), nil
+	case FormatTable:
+		return NewTableFormatter(opts), nil
+	case FormatYAML:
+		return NewYAMLFormatter(), nil
+	case FormatNDJSON:
+		return NewNDJSONFormatter(), nil
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
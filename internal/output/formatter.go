@@ -12,12 +12,25 @@ const (
 	FormatPlain Format = "plain"
 	// FormatJSON is JSON format
 	FormatJSON Format = "json"
+	// FormatTable is an aligned table with a header row
+	FormatTable Format = "table"
+	// FormatYAML is YAML format
+	FormatYAML Format = "yaml"
+	// FormatNDJSON is newline-delimited JSON (one document per line)
+	FormatNDJSON Format = "ndjson"
 )
 
+// Options holds formatter settings chosen on the command line
+type Options struct {
+	Columns   []string // Columns to show, in order (table format only; empty for defaults)
+	NoHeaders bool     // Omit the header row (table format only)
+}
+
 // Formatter formats data for output
 type Formatter interface {
 	FormatVaults(vaults []*models.Vault) (string, error)
 	FormatSecrets(secrets []*models.Secret) (string, error)
 	FormatProviders(providers []string) (string, error)
 	FormatWalkSecrets(secretsByVault map[string][]*models.SecretValue) (string, error)
+	FormatSecretValue(secret *models.SecretValue) (string, error)
 }
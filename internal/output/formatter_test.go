@@ -0,0 +1,277 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+var (
+	updated = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testVaults = []*models.Vault{
+		{Name: "app", Provider: "memory", Metadata: map[string]string{"location": "local"}},
+		{Name: "ops", Provider: "memory"},
+	}
+	testSecrets = []*models.Secret{
+		{Name: "api-key", VaultName: "app", Provider: "memory", Enabled: true},
+		{Name: "db", VaultName: "app", Provider: "memory"},
+	}
+	testProviders = []string{"azure", "memory"}
+	testWalk      = map[string][]*models.SecretValue{
+		"ops": {{Name: "b", Value: "2", VaultName: "ops", Provider: "memory"}},
+		"app": {{Name: "a", Value: "1", VaultName: "app", Provider: "memory"}},
+	}
+	testValue = &models.SecretValue{Name: "api-key", Value: "s3cret", VaultName: "app", Provider: "memory", Version: "2", UpdatedOn: &updated}
+)
+
+// methods calls each Formatter method with the shared test data
+var methods = map[string]func(f Formatter) (string, error){
+	"vaults":    func(f Formatter) (string, error) { return f.FormatVaults(testVaults) },
+	"secrets":   func(f Formatter) (string, error) { return f.FormatSecrets(testSecrets) },
+	"providers": func(f Formatter) (string, error) { return f.FormatProviders(testProviders) },
+	"walk":      func(f Formatter) (string, error) { return f.FormatWalkSecrets(testWalk) },
+	"value":     func(f Formatter) (string, error) { return f.FormatSecretValue(testValue) },
+}
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		format Format
+		method string
+		want   string
+	}{
+		{FormatPlain, "vaults", "app\nops"},
+		{FormatPlain, "secrets", "api-key\ndb"},
+		{FormatPlain, "providers", "azure\nmemory"},
+		{FormatPlain, "walk", "app:a=1\nops:b=2"},
+		{FormatPlain, "value", "s3cret"},
+
+		{FormatJSON, "vaults", `[
+  {
+    "name": "app",
+    "provider": "memory",
+    "metadata": {
+      "location": "local"
+    }
+  },
+  {
+    "name": "ops",
+    "provider": "memory"
+  }
+]`},
+		{FormatJSON, "secrets", `[
+  {
+    "name": "api-key",
+    "vault": "app",
+    "provider": "memory",
+    "enabled": true
+  },
+  {
+    "name": "db",
+    "vault": "app",
+    "provider": "memory"
+  }
+]`},
+		{FormatJSON, "providers", `[
+  "azure",
+  "memory"
+]`},
+		{FormatJSON, "walk", `{
+  "app": [
+    {
+      "name": "a",
+      "value": "1",
+      "vault": "app",
+      "provider": "memory"
+    }
+  ],
+  "ops": [
+    {
+      "name": "b",
+      "value": "2",
+      "vault": "ops",
+      "provider": "memory"
+    }
+  ]
+}`},
+		{FormatJSON, "value", `{
+  "name": "api-key",
+  "value": "s3cret",
+  "vault": "app",
+  "provider": "memory",
+  "version": "2",
+  "updatedOn": "2024-06-01T12:00:00Z"
+}`},
+
+		{FormatTable, "vaults", "NAME  PROVIDER  LOCATION\napp   memory    local\nops   memory"},
+		{FormatTable, "secrets", "NAME     VAULT  PROVIDER  ENABLED\napi-key  app    memory    true\ndb       app    memory    false"},
+		{FormatTable, "providers", "NAME\nazure\nmemory"},
+		{FormatTable, "walk", "VAULT  NAME  VALUE\napp    a     1\nops    b     2"},
+		{FormatTable, "value", "NAME     VAULT  PROVIDER  VALUE   VERSION  UPDATEDON\napi-key  app    memory    s3cret  2        2024-06-01T12:00:00Z"},
+
+		{FormatYAML, "vaults", `- name: app
+  provider: memory
+  metadata:
+    location: local
+- name: ops
+  provider: memory`},
+		{FormatYAML, "secrets", `- name: api-key
+  vault: app
+  provider: memory
+  enabled: true
+- name: db
+  vault: app
+  provider: memory`},
+		{FormatYAML, "providers", "- azure\n- memory"},
+		{FormatYAML, "walk", `app:
+  - name: a
+    value: "1"
+    vault: app
+    provider: memory
+ops:
+  - name: b
+    value: "2"
+    vault: ops
+    provider: memory`},
+		{FormatYAML, "value", `name: api-key
+value: s3cret
+vault: app
+provider: memory
+version: "2"
+updatedOn: 2024-06-01T12:00:00Z`},
+
+		{FormatNDJSON, "vaults", `{"name":"app","provider":"memory","metadata":{"location":"local"}}
+{"name":"ops","provider":"memory"}`},
+		{FormatNDJSON, "secrets", `{"name":"api-key","vault":"app","provider":"memory","enabled":true}
+{"name":"db","vault":"app","provider":"memory"}`},
+		{FormatNDJSON, "providers", `"azure"
+"memory"`},
+		{FormatNDJSON, "walk", `{"name":"a","value":"1","vault":"app","provider":"memory"}
+{"name":"b","value":"2","vault":"ops","provider":"memory"}`},
+		{FormatNDJSON, "value", `{"name":"api-key","value":"s3cret","vault":"app","provider":"memory","version":"2","updatedOn":"2024-06-01T12:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format)+"/"+tt.method, func(t *testing.T) {
+			f, err := GetFormatter(tt.format, Options{})
+			if err != nil {
+				t.Fatalf("GetFormatter: %v", err)
+			}
+			got, err := methods[tt.method](f)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormattersEmpty(t *testing.T) {
+	for _, format := range []Format{FormatPlain, FormatTable, FormatNDJSON} {
+		f, _ := GetFormatter(format, Options{NoHeaders: true})
+		for _, got := range []func() (string, error){
+			func() (string, error) { return f.FormatVaults(nil) },
+			func() (string, error) { return f.FormatSecrets(nil) },
+			func() (string, error) { return f.FormatProviders(nil) },
+		} {
+			if s, err := got(); err != nil || s != "" {
+				t.Errorf("%s: got %q, %v; want empty output", format, s, err)
+			}
+		}
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		method  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "selected columns in order",
+			opts:   Options{Columns: []string{"provider", " Name "}},
+			method: "vaults",
+			want:   "PROVIDER  NAME\nmemory    app\nmemory    ops",
+		},
+		{
+			name:   "metadata key missing on some vaults",
+			opts:   Options{Columns: []string{"name", "LOCATION"}},
+			method: "vaults",
+			want:   "NAME  LOCATION\napp   local\nops",
+		},
+		{
+			name:   "optional secret value column",
+			opts:   Options{Columns: []string{"name", "contentType", "expiresOn"}},
+			method: "value",
+			want:   "NAME     CONTENTTYPE  EXPIRESON\napi-key",
+		},
+		{
+			name:   "no headers",
+			opts:   Options{NoHeaders: true},
+			method: "secrets",
+			want:   "api-key  app  memory  true\ndb       app  memory  false",
+		},
+		{
+			name:   "no headers with columns",
+			opts:   Options{Columns: []string{"name"}, NoHeaders: true},
+			method: "providers",
+			want:   "azure\nmemory",
+		},
+		{
+			name:    "unknown column",
+			opts:    Options{Columns: []string{"name", "colour"}},
+			method:  "secrets",
+			wantErr: "unknown column: colour (available: name, vault, provider, enabled)",
+		},
+		{
+			name:    "walk has no enabled column",
+			opts:    Options{Columns: []string{"enabled"}},
+			method:  "walk",
+			wantErr: "unknown column: enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := methods[tt.method](NewTableFormatter(tt.opts))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFormatterUnsupported(t *testing.T) {
+	if _, err := GetFormatter("xml", Options{}); err == nil || err.Error() != "unsupported format: xml" {
+		t.Errorf("got %v, want unsupported format error", err)
+	}
+}
+
+func TestPlainAggregated(t *testing.T) {
+	f := NewPlainFormatter()
+
+	vaults, _ := f.FormatVaults([]*models.Vault{{Name: "kv/team", Provider: "hashicorp", Instance: "prod"}})
+	if want := "hashicorp/prod/kv%2Fteam"; vaults != want {
+		t.Errorf("vaults: got %q, want %q", vaults, want)
+	}
+
+	secrets, _ := f.FormatSecrets([]*models.Secret{{Name: "db", VaultName: "kv/team", Provider: "hashicorp", Instance: "prod"}})
+	if want := "hashicorp/prod/kv%2Fteam/db"; secrets != want {
+		t.Errorf("secrets: got %q, want %q", secrets, want)
+	}
+}
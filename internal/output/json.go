@@ -49,3 +49,12 @@ func (f *JSONFormatter) FormatWalkSecrets(secretsByVault map[string][]*models.Se
 	}
 	return string(data), nil
 }
+
+// FormatSecretValue formats a single secret with its metadata as JSON
+func (f *JSONFormatter) FormatSecretValue(secret *models.SecretValue) (string, error) {
+	data, err := json.MarshalIndent(secret, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// NDJSONFormatter outputs newline-delimited JSON (one document per line)
+type NDJSONFormatter struct{}
+
+// NewNDJSONFormatter creates a new NDJSON formatter
+func NewNDJSONFormatter() *NDJSONFormatter {
+	return &NDJSONFormatter{}
+}
+
+// FormatVaults formats vaults as one JSON object per line
+func (f *NDJSONFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
+	return marshalLines(len(vaults), func(i int) interface{} { return vaults[i] })
+}
+
+// FormatSecrets formats secrets as one JSON object per line
+func (f *NDJSONFormatter) FormatSecrets(secrets []*models.Secret) (string, error) {
+	return marshalLines(len(secrets), func(i int) interface{} { return secrets[i] })
+}
+
+// FormatProviders formats provider names as one JSON string per line
+func (f *NDJSONFormatter) FormatProviders(providers []string) (string, error) {
+	return marshalLines(len(providers), func(i int) interface{} { return providers[i] })
+}
+
+// FormatWalkSecrets formats all secrets as one JSON object per line, ordered by vault
+func (f *NDJSONFormatter) FormatWalkSecrets(secretsByVault map[string][]*models.SecretValue) (string, error) {
+	vaultNames := make([]string, 0, len(secretsByVault))
+	for name := range secretsByVault {
+		vaultNames = append(vaultNames, name)
+	}
+	sort.Strings(vaultNames)
+
+	var values []*models.SecretValue
+	for _, name := range vaultNames {
+		values = append(values, secretsByVault[name]...)
+	}
+
+	return marshalLines(len(values), func(i int) interface{} { return values[i] })
+}
+
+// FormatSecretValue formats a single secret with its metadata as one JSON line
+func (f *NDJSONFormatter) FormatSecretValue(secret *models.SecretValue) (string, error) {
+	return marshalLines(1, func(int) interface{} { return secret })
+}
+
+// marshalLines encodes n items as compact JSON, one per line
+func marshalLines(n int, item func(i int) interface{}) (string, error) {
+	lines := make([]string, n)
+	for i := 0; i < n; i++ {
+		data, err := json.Marshal(item(i))
+		if err != nil {
+			return "", err
+		}
+		lines[i] = string(data)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// NDJSONWriter streams items as NDJSON while they are produced, so large
+// listings never have to be buffered in memory
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter creates a streaming NDJSON writer
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write encodes v as a single line
+func (w *NDJSONWriter) Write(v interface{}) error {
+	return w.enc.Encode(v)
+}
@@ -1,6 +1,8 @@
 package output
 
 import (
+	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/ylchen07/smart-keyvault/pkg/models"
@@ -15,6 +17,7 @@ func NewPlainFormatter() *PlainFormatter {
 }
 
 // FormatVaults formats vaults as plain text (one name per line)
+// Vaults from an aggregated listing are qualified as provider/instance/vault.
 func (f *PlainFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
 	if len(vaults) == 0 {
 		return "", nil
@@ -22,6 +25,10 @@ func (f *PlainFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
 
 	names := make([]string, len(vaults))
 	for i, v := range vaults {
+		if v.Instance != "" {
+			names[i] = url.PathEscape(v.Provider) + "/" + url.PathEscape(v.Instance) + "/" + url.PathEscape(v.Name)
+			continue
+		}
 		names[i] = v.Name
 	}
 
@@ -29,6 +36,8 @@ func (f *PlainFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
 }
 
 // FormatSecrets formats secrets as plain text (one name per line)
+// Secrets from an aggregated listing are written as provider/instance/vault/secret
+// references that `get-secret --ref` accepts.
 func (f *PlainFormatter) FormatSecrets(secrets []*models.Secret) (string, error) {
 	if len(secrets) == 0 {
 		return "", nil
@@ -36,6 +45,10 @@ func (f *PlainFormatter) FormatSecrets(secrets []*models.Secret) (string, error)
 
 	names := make([]string, len(secrets))
 	for i, s := range secrets {
+		if s.Instance != "" {
+			names[i] = models.SecretRef{Provider: s.Provider, Instance: s.Instance, Vault: s.VaultName, Secret: s.Name}.String()
+			continue
+		}
 		names[i] = s.Name
 	}
 
@@ -58,12 +71,24 @@ func (f *PlainFormatter) FormatWalkSecrets(secretsByVault map[string][]*models.S
 		return "", nil
 	}
 
+	// Map iteration order is random, so emit vaults in sorted order
+	vaultNames := make([]string, 0, len(secretsByVault))
+	for vaultName := range secretsByVault {
+		vaultNames = append(vaultNames, vaultName)
+	}
+	sort.Strings(vaultNames)
+
 	var lines []string
-	for vaultName, secrets := range secretsByVault {
-		for _, secret := range secrets {
+	for _, vaultName := range vaultNames {
+		for _, secret := range secretsByVault[vaultName] {
 			lines = append(lines, vaultName+":"+secret.Name+"="+secret.Value)
 		}
 	}
 
 	return strings.Join(lines, "\n"), nil
 }
+
+// FormatSecretValue formats a single secret as its bare value
+func (f *PlainFormatter) FormatSecretValue(secret *models.SecretValue) (string, error) {
+	return secret.Value, nil
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Sort keys accepted by --sort
+const (
+	// SortNone keeps provider order (providers already return items sorted by name)
+	SortNone = ""
+	// SortName orders by name, then vault
+	SortName = "name"
+	// SortVault orders by vault, then name
+	SortVault = "vault"
+	// SortUpdated orders by last update (newest first); items without a
+	// timestamp come last, ordered by name
+	SortUpdated = "updated"
+)
+
+// ValidateSortKey checks that key is a supported sort key
+func ValidateSortKey(key string) error {
+	switch key {
+	case SortNone, SortName, SortVault, SortUpdated:
+		return nil
+	default:
+		return fmt.Errorf("unsupported sort key: %s (use name, vault or updated)", key)
+	}
+}
+
+// SortVaults orders vaults by key; vaults only support name ordering, so
+// every key other than SortNone sorts by name (then provider and instance)
+func SortVaults(vaults []*models.Vault, key string) {
+	if key == SortNone {
+		return
+	}
+	sort.SliceStable(vaults, func(i, j int) bool {
+		a, b := vaults[i], vaults[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Instance < b.Instance
+	})
+}
+
+// SortSecrets orders secrets by key
+func SortSecrets(secrets []*models.Secret, key string) {
+	if key == SortNone {
+		return
+	}
+	sort.SliceStable(secrets, func(i, j int) bool {
+		a, b := secrets[i], secrets[j]
+		if key == SortUpdated {
+			if less, decided := newerFirst(a.UpdatedOn, b.UpdatedOn); decided {
+				return less
+			}
+		}
+		return lessByKey(key, a.Name, a.VaultName, a.Provider+"/"+a.Instance, b.Name, b.VaultName, b.Provider+"/"+b.Instance)
+	})
+}
+
+// lessByKey compares by name then vault (or vault then name for SortVault),
+// using the provider/instance as the final tie-breaker
+func lessByKey(key, nameA, vaultA, sourceA, nameB, vaultB, sourceB string) bool {
+	first := [2]string{nameA, nameB}
+	second := [2]string{vaultA, vaultB}
+	if key == SortVault {
+		first, second = second, first
+	}
+
+	if first[0] != first[1] {
+		return first[0] < first[1]
+	}
+	if second[0] != second[1] {
+		return second[0] < second[1]
+	}
+	return sourceA < sourceB
+}
+
+// newerFirst orders timestamps newest first with missing timestamps last
+// decided is false when both are missing or equal, so callers fall back to names.
+func newerFirst(a, b *time.Time) (less, decided bool) {
+	switch {
+	case a == nil && b == nil:
+		return false, false
+	case a == nil:
+		return false, true
+	case b == nil:
+		return true, true
+	case a.Equal(*b):
+		return false, false
+	default:
+		return a.After(*b), true
+	}
+}
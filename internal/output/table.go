@@ -0,0 +1,260 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// TableFormatter outputs aligned columns with an optional header row
+type TableFormatter struct {
+	columns   []string
+	noHeaders bool
+}
+
+// NewTableFormatter creates a new table formatter
+func NewTableFormatter(opts Options) *TableFormatter {
+	return &TableFormatter{
+		columns:   opts.Columns,
+		noHeaders: opts.NoHeaders,
+	}
+}
+
+// FormatVaults formats vaults as a table
+// Columns: name, provider, instance and any metadata key (e.g. location,
+// resourceGroup, description). By default all metadata keys present are shown.
+func (f *TableFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
+	defaults := []string{"name", "provider"}
+	if anyVault(vaults, func(v *models.Vault) bool { return v.Instance != "" }) {
+		defaults = append(defaults, "instance")
+	}
+	defaults = append(defaults, metadataKeys(vaults)...)
+
+	return f.render(defaults, len(vaults), func(i int, column string) (string, bool) {
+		v := vaults[i]
+		switch column {
+		case "name":
+			return v.Name, true
+		case "provider":
+			return v.Provider, true
+		case "instance":
+			return v.Instance, true
+		}
+		for key, value := range v.Metadata {
+			if strings.EqualFold(key, column) {
+				return value, true
+			}
+		}
+		// Unknown keys are valid metadata columns for other vaults
+		return "", anyVault(vaults, func(v *models.Vault) bool { return hasMetadataKey(v, column) })
+	})
+}
+
+// FormatSecrets formats secrets as a table
+// Columns: name, vault, provider, instance, enabled
+func (f *TableFormatter) FormatSecrets(secrets []*models.Secret) (string, error) {
+	defaults := []string{"name", "vault", "provider"}
+	for _, s := range secrets {
+		if s.Instance != "" {
+			defaults = append(defaults, "instance")
+			break
+		}
+	}
+	defaults = append(defaults, "enabled")
+
+	return f.render(defaults, len(secrets), func(i int, column string) (string, bool) {
+		s := secrets[i]
+		switch column {
+		case "name":
+			return s.Name, true
+		case "vault":
+			return s.VaultName, true
+		case "provider":
+			return s.Provider, true
+		case "instance":
+			return s.Instance, true
+		case "enabled":
+			return strconv.FormatBool(s.Enabled), true
+		}
+		return "", false
+	})
+}
+
+// FormatProviders formats provider names as a table
+// Columns: name
+func (f *TableFormatter) FormatProviders(providers []string) (string, error) {
+	return f.render([]string{"name"}, len(providers), func(i int, column string) (string, bool) {
+		if column == "name" {
+			return providers[i], true
+		}
+		return "", false
+	})
+}
+
+// FormatWalkSecrets formats all secrets as a table sorted by vault
+// Columns: vault, name, value, provider
+func (f *TableFormatter) FormatWalkSecrets(secretsByVault map[string][]*models.SecretValue) (string, error) {
+	vaultNames := make([]string, 0, len(secretsByVault))
+	for name := range secretsByVault {
+		vaultNames = append(vaultNames, name)
+	}
+	sort.Strings(vaultNames)
+
+	var rows []*models.SecretValue
+	for _, name := range vaultNames {
+		rows = append(rows, secretsByVault[name]...)
+	}
+
+	return f.render([]string{"vault", "name", "value"}, len(rows), func(i int, column string) (string, bool) {
+		s := rows[i]
+		switch column {
+		case "vault":
+			return s.VaultName, true
+		case "name":
+			return s.Name, true
+		case "value":
+			return s.Value, true
+		case "provider":
+			return s.Provider, true
+		}
+		return "", false
+	})
+}
+
+// FormatSecretValue formats a single secret as a one-row table
+// Columns: name, vault, provider, value, version, contentType, createdOn,
+// updatedOn, expiresOn
+func (f *TableFormatter) FormatSecretValue(secret *models.SecretValue) (string, error) {
+	defaults := []string{"name", "vault", "provider", "value", "version", "updatedon"}
+
+	return f.render(defaults, 1, func(i int, column string) (string, bool) {
+		switch column {
+		case "name":
+			return secret.Name, true
+		case "vault":
+			return secret.VaultName, true
+		case "provider":
+			return secret.Provider, true
+		case "value":
+			return secret.Value, true
+		case "version":
+			return secret.Version, true
+		case "contenttype":
+			return secret.ContentType, true
+		case "createdon":
+			return formatTime(secret.CreatedOn), true
+		case "updatedon":
+			return formatTime(secret.UpdatedOn), true
+		case "expireson":
+			return formatTime(secret.ExpiresOn), true
+		}
+		return "", false
+	})
+}
+
+// render writes n rows using cell to look up each column value
+// cell reports false for columns the item type does not have.
+func (f *TableFormatter) render(defaults []string, n int, cell func(i int, column string) (string, bool)) (string, error) {
+	columns := defaults
+	if len(f.columns) > 0 {
+		columns = make([]string, len(f.columns))
+		for i, c := range f.columns {
+			columns[i] = strings.ToLower(strings.TrimSpace(c))
+		}
+	}
+
+	// Validate columns against the first item so typos fail loudly
+	for _, column := range columns {
+		if n == 0 {
+			break
+		}
+		if _, ok := cell(0, column); !ok {
+			return "", fmt.Errorf("unknown column: %s (available: %s)", column, strings.Join(defaults, ", "))
+		}
+	}
+
+	rows := make([][]string, 0, n+1)
+	if !f.noHeaders {
+		headers := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = strings.ToUpper(column)
+		}
+		rows = append(rows, headers)
+	}
+
+	for i := 0; i < n; i++ {
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			row[j], _ = cell(i, column)
+		}
+		rows = append(rows, row)
+	}
+
+	return WriteTable(rows), nil
+}
+
+// WriteTable aligns rows into space-separated columns
+func WriteTable(rows [][]string) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	// Trim trailing padding and the final newline (callers use fmt.Println)
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// metadataKeys returns the sorted union of metadata keys across vaults
+func metadataKeys(vaults []*models.Vault) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, v := range vaults {
+		for key := range v.Metadata {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasMetadataKey reports whether a vault has a metadata key (case-insensitive)
+func hasMetadataKey(v *models.Vault, column string) bool {
+	for key := range v.Metadata {
+		if strings.EqualFold(key, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyVault reports whether pred holds for at least one vault
+func anyVault(vaults []*models.Vault, pred func(*models.Vault) bool) bool {
+	for _, v := range vaults {
+		if pred(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTime renders an optional timestamp in RFC 3339 (empty when unset)
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
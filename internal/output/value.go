@@ -0,0 +1,88 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Mask modes for secret values
+const (
+	// MaskNone shows the value unchanged
+	MaskNone = ""
+	// MaskLength replaces the value with its length in characters
+	MaskLength = "length"
+	// MaskLast4 shows only the last 4 characters, e.g. "****wxyz"
+	MaskLast4 = "last4"
+)
+
+// Encodings for secret values
+const (
+	// EncodingRaw outputs the value as stored
+	EncodingRaw = "raw"
+	// EncodingBase64 outputs the value base64-encoded (standard alphabet)
+	EncodingBase64 = "base64"
+	// EncodingHex outputs the value hex-encoded
+	EncodingHex = "hex"
+)
+
+// ValueOptions controls how secret values are rendered
+type ValueOptions struct {
+	Mask     string // MaskNone, MaskLength or MaskLast4
+	Encoding string // EncodingRaw (default), EncodingBase64 or EncodingHex
+}
+
+// TransformValue returns a copy of secret with its value (and fields)
+// encoded and then masked according to opts. The input is never modified.
+func TransformValue(secret *models.SecretValue, opts ValueOptions) (*models.SecretValue, error) {
+	out := *secret
+
+	value, err := transform(secret.Value, opts)
+	if err != nil {
+		return nil, err
+	}
+	out.Value = value
+
+	if len(secret.Fields) > 0 {
+		out.Fields = make(map[string]string, len(secret.Fields))
+		for k, v := range secret.Fields {
+			if out.Fields[k], err = transform(v, opts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &out, nil
+}
+
+// transform encodes and masks a single value
+func transform(value string, opts ValueOptions) (string, error) {
+	switch opts.Encoding {
+	case "", EncodingRaw:
+	case EncodingBase64:
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	case EncodingHex:
+		value = hex.EncodeToString([]byte(value))
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s (use raw, base64 or hex)", opts.Encoding)
+	}
+
+	switch opts.Mask {
+	case MaskNone:
+		return value, nil
+	case MaskLength:
+		return strconv.Itoa(len([]rune(value))), nil
+	case MaskLast4:
+		runes := []rune(value)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes)), nil
+		}
+		return "****" + string(runes[len(runes)-4:]), nil
+	default:
+		return "", fmt.Errorf("unsupported mask: %s (use length or last4)", opts.Mask)
+	}
+}
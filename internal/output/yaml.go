@@ -0,0 +1,56 @@
+package output
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// YAMLFormatter outputs YAML format
+type YAMLFormatter struct{}
+
+// NewYAMLFormatter creates a new YAML formatter
+func NewYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+// FormatVaults formats vaults as YAML
+func (f *YAMLFormatter) FormatVaults(vaults []*models.Vault) (string, error) {
+	return marshalYAML(vaults)
+}
+
+// FormatSecrets formats secrets as YAML
+func (f *YAMLFormatter) FormatSecrets(secrets []*models.Secret) (string, error) {
+	return marshalYAML(secrets)
+}
+
+// FormatProviders formats provider names as YAML
+func (f *YAMLFormatter) FormatProviders(providers []string) (string, error) {
+	return marshalYAML(providers)
+}
+
+// FormatWalkSecrets formats all secrets grouped by vault as YAML
+func (f *YAMLFormatter) FormatWalkSecrets(secretsByVault map[string][]*models.SecretValue) (string, error) {
+	return marshalYAML(secretsByVault)
+}
+
+// FormatSecretValue formats a single secret with its metadata as YAML
+func (f *YAMLFormatter) FormatSecretValue(secret *models.SecretValue) (string, error) {
+	return marshalYAML(secret)
+}
+
+// marshalYAML encodes v with two-space indentation and no trailing newline
+func marshalYAML(v interface{}) (string, error) {
+	var sb strings.Builder
+	enc := yaml.NewEncoder(&sb)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
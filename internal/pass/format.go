@@ -0,0 +1,56 @@
+package pass
+
+import (
+	"strings"
+)
+
+// entry is a decrypted password-store entry
+type entry struct {
+	password string
+	fields   map[string]string
+}
+
+// parseEntry parses the pass format: the first line is the password and
+// later "key: value" lines are fields. Other lines (notes, otpauth:// URLs,
+// gopass's "---" separator) are kept in the "notes" field.
+// The password is also available as the "password" field.
+func parseEntry(data []byte) *entry {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	e := &entry{
+		password: lines[0],
+		fields:   map[string]string{"password": lines[0]},
+	}
+
+	var notes []string
+	for _, line := range lines[1:] {
+		if key, value, ok := parseField(line); ok {
+			e.fields[key] = value
+			continue
+		}
+		if line != "" && line != "---" {
+			notes = append(notes, line)
+		}
+	}
+	if len(notes) > 0 {
+		e.fields["notes"] = strings.Join(notes, "\n")
+	}
+	return e
+}
+
+// parseField splits a "key: value" line; URLs such as "otpauth://..." are
+// not fields because the colon is not followed by a space
+func parseField(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	if value != "" && value[0] != ' ' && value[0] != '\t' {
+		return "", "", false
+	}
+
+	key = strings.ToLower(key)
+	if key == "password" {
+		return "", "", false // Never shadow the first line
+	}
+	return key, strings.TrimSpace(value), true
+}
@@ -0,0 +1,55 @@
+package pass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+)
+
+// decrypt decrypts a .gpg file through gpg and the user's gpg-agent
+// Passphrases are never handled here: the agent asks through its own
+// pinentry or uses its cached key.
+func decrypt(ctx context.Context, gpg, path string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, gpg, "--quiet", "--yes", "--batch", "--decrypt", path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		clear(stdout.Bytes())
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, provider.NewError(provider.ErrUnavailable, fmt.Errorf("%s not found in PATH", gpg))
+		}
+		return nil, classify(strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// classify maps gpg's diagnostics to provider error kinds
+func classify(stderr string, err error) error {
+	// gpg's last line is the outcome; earlier ones are context
+	msg := err.Error()
+	if lines := strings.Split(stderr, "\n"); stderr != "" {
+		msg = lines[len(lines)-1]
+	}
+	wrapped := fmt.Errorf("failed to decrypt: %s", msg)
+
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "no secret key"):
+		// Encrypted to keys we do not hold
+		return provider.NewError(provider.ErrPermissionDenied, wrapped)
+	case strings.Contains(lower, "pinentry"), strings.Contains(lower, "inappropriate ioctl"),
+		strings.Contains(lower, "bad passphrase"), strings.Contains(lower, "operation cancelled"),
+		strings.Contains(lower, "no agent running"):
+		// The key is locked and the agent could not ask for its passphrase
+		return provider.NewError(provider.ErrAuthExpired, wrapped)
+	default:
+		return wrapped
+	}
+}
@@ -0,0 +1,235 @@
+package pass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+const (
+	// rootVault is the vault holding entries at the top of the store
+	rootVault = "."
+	// entryExt is the extension of password-store entries
+	entryExt = ".gpg"
+)
+
+// Provider implements the provider.Provider interface for a pass (or gopass)
+// password store: top-level directories are vaults and .gpg files secrets
+type Provider struct {
+	dir string
+	gpg string
+}
+
+// Schema declares the settings of a pass instance
+var Schema = &provider.Schema{
+	Fields: []provider.Field{
+		{Name: "directory"},
+		{Name: "gpg", Default: "gpg"},
+	},
+}
+
+// NewProvider creates a new password-store provider
+// Configuration options:
+//   - "directory" (string): store directory (optional, defaults to $PASSWORD_STORE_DIR or ~/.password-store)
+//   - "gpg" (string): gpg binary (optional, defaults to "gpg")
+func NewProvider(cfg *provider.Config) (provider.Provider, error) {
+	var dir, gpg string
+	if cfg != nil && cfg.Settings != nil {
+		if v, ok := cfg.Settings["directory"].(string); ok {
+			dir = v
+		}
+		if v, ok := cfg.Settings["gpg"].(string); ok {
+			gpg = v
+		}
+	}
+
+	if dir == "" {
+		dir = os.Getenv("PASSWORD_STORE_DIR")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".password-store")
+	}
+	if gpg == "" {
+		gpg = "gpg"
+	}
+
+	return &Provider{dir: dir, gpg: gpg}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "pass"
+}
+
+// ListVaults returns the store's top-level directories, plus "." when
+// entries sit at the top of the store
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("failed to list vaults: password store %s does not exist", p.dir))
+		}
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+
+	var vaults []*models.Vault
+	hasRoot := false
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue // .git, .gpg-id, .public-keys
+		}
+		if e.IsDir() {
+			vaults = append(vaults, &models.Vault{
+				Name:     e.Name(),
+				Provider: "pass",
+				Metadata: map[string]string{"path": filepath.Join(p.dir, e.Name())},
+			})
+		} else if strings.HasSuffix(e.Name(), entryExt) {
+			hasRoot = true
+		}
+	}
+	if hasRoot {
+		vaults = append(vaults, &models.Vault{Name: rootVault, Provider: "pass", Metadata: map[string]string{"path": p.dir}})
+	}
+
+	sort.Slice(vaults, func(i, j int) bool { return vaults[i].Name < vaults[j].Name })
+	return vaults, nil
+}
+
+// ListSecrets returns the entries of a vault, nested ones as "dir/name"
+// The root vault lists only the entries at the top of the store.
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	root, err := p.vaultDir(vaultName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var secrets []*models.Secret
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && vaultName == rootVault {
+				return filepath.SkipDir // Subdirectories are vaults of their own
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), entryExt) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		secret := &models.Secret{
+			Name:      filepath.ToSlash(strings.TrimSuffix(rel, entryExt)),
+			VaultName: vaultName,
+			Provider:  "pass",
+			Enabled:   true,
+		}
+		if info, err := d.Info(); err == nil {
+			modTime := info.ModTime().UTC().Truncate(time.Second)
+			secret.UpdatedOn = &modTime
+		}
+		secrets = append(secrets, secret)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("failed to list secrets: vault '%s' not found", vaultName))
+		}
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// GetSecret decrypts an entry; the first line is the value and "key: value"
+// lines become fields
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	path, err := p.entryPath(vaultName, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, provider.NewError(provider.ErrNotFound, fmt.Errorf("failed to get secret: secret not found"))
+		}
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	data, err := decrypt(ctx, p.gpg, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	defer clear(data)
+
+	e := parseEntry(data)
+	modTime := info.ModTime().UTC().Truncate(time.Second)
+	return &models.SecretValue{
+		Name:      secretName,
+		Value:     e.password,
+		VaultName: vaultName,
+		Provider:  "pass",
+		Fields:    e.fields,
+		UpdatedOn: &modTime,
+	}, nil
+}
+
+// SupportsFeature reports no optional features
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	return false
+}
+
+// vaultDir returns the directory of a vault after validating its name
+func (p *Provider) vaultDir(vault string) (string, error) {
+	if vault == rootVault {
+		return p.dir, nil
+	}
+	if vault == "" || strings.HasPrefix(vault, ".") || strings.ContainsAny(vault, `/\`) {
+		return "", fmt.Errorf("invalid vault name '%s'", vault)
+	}
+	return filepath.Join(p.dir, vault), nil
+}
+
+// entryPath returns the .gpg file of a secret, refusing names that would
+// leave the vault
+func (p *Provider) entryPath(vault, secret string) (string, error) {
+	dir, err := p.vaultDir(vault)
+	if err != nil {
+		return "", err
+	}
+
+	rel := filepath.FromSlash(secret)
+	if secret == "" || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid secret name '%s'", secret)
+	}
+	if vault == rootVault && strings.Contains(secret, "/") {
+		return "", fmt.Errorf("invalid secret name '%s' (nested entries belong to the vault named after their top-level directory)", secret)
+	}
+	return filepath.Join(dir, rel+entryExt), nil
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	sdk "github.com/ylchen07/smart-keyvault/pkg/plugin"
+)
+
+// Discover returns the plugin binaries in dir and on PATH keyed by provider
+// name; dir wins over PATH and earlier PATH entries over later ones
+func Discover(dir string) map[string]string {
+	dirs := filepath.SplitList(os.Getenv("PATH"))
+	if dir != "" {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	found := make(map[string]string)
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || found[name] != "" {
+				continue
+			}
+			path := filepath.Join(d, entry.Name())
+			if isExecutable(path) {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// RegisterDiscovered registers every discovered plugin under its provider
+// name and returns the names registered
+// Built-in providers are never replaced by a plugin of the same name.
+func RegisterDiscovered(dir string) []string {
+	var names []string
+	for name, path := range Discover(dir) {
+		if provider.IsRegistered(name) {
+			continue
+		}
+		provider.Register(name, Factory(name, path), nil)
+		names = append(names, name)
+	}
+	return names
+}
+
+// pluginName returns the provider name served by a binary file name
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		var ok bool
+		if file, ok = strings.CutSuffix(strings.ToLower(file), ".exe"); !ok {
+			return "", false
+		}
+	}
+	name, ok := strings.CutPrefix(file, sdk.BinaryPrefix)
+	return name, ok && name != ""
+}
+
+// isExecutable reports whether path is a regular file the user may run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+	sdk "github.com/ylchen07/smart-keyvault/pkg/plugin"
+)
+
+// handshakeTimeout bounds starting a plugin and creating its provider
+const handshakeTimeout = 10 * time.Second
+
+// Provider implements provider.Provider by forwarding to a plugin process
+// One process serves one instance and exits when its stdin is closed,
+// which happens at the latest when the CLI or agent exits.
+type Provider struct {
+	name     string
+	features map[provider.Feature]bool
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	failed error // Set once the process can no longer be trusted
+}
+
+// Factory returns a provider factory starting the plugin binary at path
+func Factory(name, path string) provider.ProviderFactory {
+	return func(cfg *provider.Config) (provider.Provider, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+		defer cancel()
+		return Start(ctx, name, path, cfg)
+	}
+}
+
+// Start runs a plugin binary and hands it the instance's settings
+func Start(ctx context.Context, name, path string, cfg *provider.Config) (*Provider, error) {
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr // Plugin diagnostics go straight to the user
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, provider.NewError(provider.ErrUnavailable, fmt.Errorf("failed to start plugin %s: %w", name, err))
+	}
+
+	p := &Provider{
+		name:     name,
+		features: make(map[provider.Feature]bool),
+		cmd:      cmd,
+		stdin:    stdin,
+		stdout:   bufio.NewReader(stdout),
+	}
+
+	req := &sdk.Request{Op: sdk.OpHandshake, Protocol: sdk.ProtocolVersion, Provider: name}
+	if cfg != nil {
+		req.Instance = cfg.Instance
+		req.Settings = cfg.Settings
+	}
+	resp, err := p.call(ctx, req)
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if resp.Protocol != sdk.ProtocolVersion {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s speaks protocol %d, expected %d", name, resp.Protocol, sdk.ProtocolVersion)
+	}
+	for _, featureName := range resp.Features {
+		if feature, ok := sdk.FeatureByName(featureName); ok {
+			p.features[feature] = true
+		}
+	}
+	return p, nil
+}
+
+// Close stops the plugin process
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stop(errors.New("plugin closed"))
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// ListVaults returns the plugin's vaults
+func (p *Provider) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpListVaults})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Vaults, nil
+}
+
+// ListSecrets returns the secrets of a vault
+func (p *Provider) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpListSecrets, Vault: vaultName})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Secrets, nil
+}
+
+// GetSecret retrieves a secret value
+func (p *Provider) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpGetSecret, Vault: vaultName, Secret: secretName})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("plugin %s returned no value", p.name)
+	}
+	return resp.Value, nil
+}
+
+// GetSecretVersion retrieves a specific version of a secret
+func (p *Provider) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpGetVersion, Vault: vaultName, Secret: secretName, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("plugin %s returned no value", p.name)
+	}
+	return resp.Value, nil
+}
+
+// GetSecretMetadata returns a secret's metadata without its value
+func (p *Provider) GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpSecretMetadata, Vault: vaultName, Secret: secretName})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Secret, nil
+}
+
+// SetSecret creates or updates a secret
+func (p *Provider) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	resp, err := p.call(ctx, &sdk.Request{Op: sdk.OpSetSecret, Vault: vaultName, Secret: secretName, Value: secret})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Secret, nil
+}
+
+// SupportsFeature reports the features announced in the handshake
+func (p *Provider) SupportsFeature(feature provider.Feature) bool {
+	return p.features[feature]
+}
+
+// call sends one request and waits for its response
+// A cancelled call kills the plugin, since its late response would answer
+// the next request.
+func (p *Provider) call(ctx context.Context, req *sdk.Request) (*sdk.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failed != nil {
+		return nil, p.failed
+	}
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return nil, p.fail(fmt.Errorf("failed to send plugin request: %w", err))
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := p.stdout.ReadBytes('\n')
+		done <- result{line, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		p.stop(ctx.Err())
+		<-done
+		return nil, ctx.Err()
+	}
+	if res.err != nil {
+		return nil, p.fail(fmt.Errorf("plugin %s exited: %w", p.name, res.err))
+	}
+
+	var resp sdk.Response
+	if err := json.Unmarshal(res.line, &resp); err != nil {
+		return nil, p.fail(fmt.Errorf("invalid plugin response: %w", err))
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// fail stops the plugin after a protocol error and returns err
+func (p *Provider) fail(err error) error {
+	p.stop(provider.NewError(provider.ErrUnavailable, err))
+	return p.failed
+}
+
+// stop kills the plugin process; later calls return reason
+func (p *Provider) stop(reason error) error {
+	if p.failed == nil {
+		p.failed = reason
+	}
+	p.stdin.Close()
+	if p.cmd.Process != nil && p.cmd.ProcessState == nil {
+		p.cmd.Process.Kill()
+		return p.cmd.Wait()
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// ConfirmFunc asks the user to confirm an operation
+// It returns an error when the operation must not proceed.
+type ConfirmFunc func(prompt string) error
+
+// Guard wraps a provider and enforces policies before every provider call
+// Refused operations return a *Violation classified as permission denied.
+type Guard struct {
+	inner    provider.Provider
+	engine   *Engine
+	command  string
+	provider string
+	instance string
+	confirm  ConfirmFunc
+
+	walked *WalkCounter
+
+	mu        sync.Mutex
+	confirmed map[string]bool // Policies already confirmed in this run
+}
+
+// WalkCounter counts the secrets listed by one run of a bulk command
+// Guards sharing a counter apply max_walk_secrets to their combined total,
+// so a walk across several instances is limited as a whole.
+type WalkCounter struct {
+	mu sync.Mutex
+	n  int
+}
+
+// add adds n listed secrets and returns the new total
+func (c *WalkCounter) add(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += n
+	return c.n
+}
+
+// Wrap returns p guarded by the policies in e for command on the given
+// provider instance. p is returned unchanged when no policies are configured.
+// Walk limits count against walked; a nil walked limits this instance alone.
+func Wrap(p provider.Provider, e *Engine, command, providerName, instance string, confirm ConfirmFunc, walked *WalkCounter) provider.Provider {
+	if e.Empty() {
+		return p
+	}
+	if walked == nil {
+		walked = &WalkCounter{}
+	}
+	return &Guard{
+		inner:     p,
+		engine:    e,
+		command:   command,
+		provider:  providerName,
+		instance:  instance,
+		confirm:   confirm,
+		walked:    walked,
+		confirmed: make(map[string]bool),
+	}
+}
+
+// Name returns the wrapped provider name
+func (g *Guard) Name() string {
+	return g.inner.Name()
+}
+
+// SupportsFeature forwards to the wrapped provider
+func (g *Guard) SupportsFeature(feature provider.Feature) bool {
+	return g.inner.SupportsFeature(feature)
+}
+
+// ListVaults lists vaults, hiding those the policies refuse
+func (g *Guard) ListVaults(ctx context.Context) ([]*models.Vault, error) {
+	if err := g.enforce(""); err != nil {
+		return nil, err
+	}
+
+	vaults, err := g.inner.ListVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := vaults[:0]
+	for _, v := range vaults {
+		if g.visible(v.Name) {
+			visible = append(visible, v)
+		}
+	}
+	return visible, nil
+}
+
+// ListSecrets lists the secrets of a vault the policies allow
+func (g *Guard) ListSecrets(ctx context.Context, vaultName string) ([]*models.Secret, error) {
+	if err := g.enforce(vaultName); err != nil {
+		return nil, err
+	}
+
+	secrets, err := g.inner.ListSecrets(ctx, vaultName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.countWalk(vaultName, len(secrets)); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// GetSecret reads a secret from a vault the policies allow
+func (g *Guard) GetSecret(ctx context.Context, vaultName, secretName string) (*models.SecretValue, error) {
+	if err := g.enforce(vaultName); err != nil {
+		return nil, err
+	}
+	return g.inner.GetSecret(ctx, vaultName, secretName)
+}
+
+// GetSecretVersion reads a secret version from a vault the policies allow
+func (g *Guard) GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error) {
+	if err := g.enforce(vaultName); err != nil {
+		return nil, err
+	}
+
+	versioner, ok := g.inner.(provider.Versioner)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support reading secret versions", g.inner.Name())
+	}
+	return versioner.GetSecretVersion(ctx, vaultName, secretName, version)
+}
+
+// SetSecret writes a secret to a vault the policies allow
+func (g *Guard) SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error) {
+	if err := g.enforce(vaultName); err != nil {
+		return nil, err
+	}
+
+	writer, ok := g.inner.(provider.Writer)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support writing secrets", g.inner.Name())
+	}
+	return writer.SetSecret(ctx, vaultName, secretName, secret)
+}
+
+// StreamVaults streams vaults, hiding those the policies refuse
+func (g *Guard) StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error] {
+	return func(yield func(*models.Vault, error) bool) {
+		if err := g.enforce(""); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for v, err := range provider.StreamVaults(ctx, g.inner) {
+			if err == nil && !g.visible(v.Name) {
+				continue
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// StreamSecrets streams the secrets of a vault the policies allow
+func (g *Guard) StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error] {
+	return func(yield func(*models.Secret, error) bool) {
+		if err := g.enforce(vaultName); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for s, err := range provider.StreamSecrets(ctx, g.inner, vaultName) {
+			if err == nil {
+				if limitErr := g.countWalk(vaultName, 1); limitErr != nil {
+					yield(nil, limitErr)
+					return
+				}
+			}
+			if !yield(s, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// GetSecretMetadata reads secret metadata from a vault the policies allow
+// Providers without metadata reads return the bare secret, as if its
+// timestamps were unknown.
+func (g *Guard) GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error) {
+	if err := g.enforce(vaultName); err != nil {
+		return nil, err
+	}
+
+	if reader, ok := g.inner.(provider.MetadataReader); ok {
+		return reader.GetSecretMetadata(ctx, vaultName, secretName)
+	}
+	return &models.Secret{Name: secretName, VaultName: vaultName, Provider: g.inner.Name(), Enabled: true}, nil
+}
+
+// scope returns the scope of an operation on vault ("" for instance-wide)
+func (g *Guard) scope(vault string) Scope {
+	return Scope{Provider: g.provider, Instance: g.instance, Vault: vault}
+}
+
+// enforce refuses denied operations and asks for pending confirmations
+func (g *Guard) enforce(vault string) error {
+	d := g.engine.Check(g.command, g.scope(vault))
+	if d.Violation != nil {
+		return provider.NewError(provider.ErrPermissionDenied, d.Violation)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, name := range d.Confirm {
+		if g.confirmed[name] {
+			continue
+		}
+
+		prompt := fmt.Sprintf("Policy %q requires confirmation for %s on %s", name, g.command, g.scope(vault))
+		if err := g.confirm(prompt); err != nil {
+			return provider.NewError(provider.ErrPermissionDenied, &Violation{
+				Policy:  name,
+				Command: g.command,
+				Scope:   g.scope(vault),
+				Reason:  err.Error(),
+			})
+		}
+		g.confirmed[name] = true
+	}
+
+	return nil
+}
+
+// visible reports whether a listed vault may be shown
+func (g *Guard) visible(vault string) bool {
+	return g.engine.Check(g.command, g.scope(vault)).Violation == nil
+}
+
+// countWalk adds n listed secrets to the running total of a bulk command and
+// refuses the walk once it exceeds the smallest applicable limit
+func (g *Guard) countWalk(vault string, n int) error {
+	d := g.engine.Check(g.command, g.scope(vault))
+	if d.MaxWalkSecrets == 0 {
+		return nil
+	}
+
+	if g.walked.add(n) > d.MaxWalkSecrets {
+		return provider.NewError(provider.ErrPermissionDenied, &Violation{
+			Policy:  d.MaxWalkPolicy,
+			Command: g.command,
+			Scope:   g.scope(vault),
+			Reason:  fmt.Sprintf("walk exceeds %d secrets", d.MaxWalkSecrets),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/ylchen07/smart-keyvault/internal/config"
+)
+
+// Command classes usable in policies instead of individual command names
+const (
+	// ClassList covers commands that list names and metadata only
+	ClassList = "list"
+	// ClassRead covers commands that read a single secret value
+	ClassRead = "read"
+	// ClassBulkRead covers commands that read many secret values at once
+	ClassBulkRead = "bulk-read"
+	// ClassExport covers commands that write secret values out of the tool
+	ClassExport = "export"
+	// ClassWrite covers commands that create or update secrets
+	ClassWrite = "write"
+	// ClassDelete covers commands that delete secrets
+	ClassDelete = "delete"
+)
+
+// commandClasses maps commands to their class
+var commandClasses = map[string]string{
+	"list-vaults":  ClassList,
+	"list-secrets": ClassList,
+	"audit expiry": ClassList,
+	"search":       ClassList,
+	"get-secret":   ClassRead,
+	"copy":         ClassRead,
+	"config-ref":   ClassRead,
+	"walk-secrets": ClassBulkRead,
+	"lint":         ClassBulkRead,
+	"set-secret":   ClassWrite,
+}
+
+// ClassOf returns the class of a command ("" if it has none)
+func ClassOf(command string) string {
+	return commandClasses[command]
+}
+
+// Scope is the location an operation touches
+// An empty Vault means an instance-wide operation such as listing vaults.
+type Scope struct {
+	Provider string
+	Instance string
+	Vault    string
+}
+
+// String returns the scope as provider/instance[/vault]
+func (s Scope) String() string {
+	if s.Vault == "" {
+		return s.Provider + "/" + s.Instance
+	}
+	return s.Provider + "/" + s.Instance + "/" + s.Vault
+}
+
+// Decision is the outcome of evaluating policies for one operation
+type Decision struct {
+	Violation      *Violation // Set when the operation is refused
+	Confirm        []string   // Names of policies that require confirmation
+	MaxWalkSecrets int        // Smallest applicable walk limit (0 is unlimited)
+	MaxWalkPolicy  string     // Policy that set MaxWalkSecrets
+}
+
+// Violation explains why a policy refused an operation
+type Violation struct {
+	Policy  string
+	Command string
+	Scope   Scope
+	Reason  string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("policy %q refuses %s on %s: %s", v.Policy, v.Command, v.Scope, v.Reason)
+}
+
+// Engine evaluates the configured policies
+type Engine struct {
+	policies []config.Policy
+}
+
+// New validates policies and creates an engine
+// Policies without a name are named after their position.
+func New(policies []config.Policy) (*Engine, error) {
+	e := &Engine{policies: make([]config.Policy, len(policies))}
+	for i, p := range policies {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("policies[%d]", i)
+		}
+		for _, pattern := range []string{p.Provider, p.Instance, p.Vault} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("policy %q: invalid pattern %q: %w", p.Name, pattern, err)
+			}
+		}
+		if p.MaxWalkSecrets < 0 {
+			return nil, fmt.Errorf("policy %q: max_walk_secrets must not be negative", p.Name)
+		}
+		e.policies[i] = p
+	}
+	return e, nil
+}
+
+// Empty reports whether no policies are configured
+func (e *Engine) Empty() bool {
+	return e == nil || len(e.policies) == 0
+}
+
+// Check evaluates every policy matching scope for command
+// Deny takes precedence over allow; confirmations and walk limits from all
+// matching policies are combined.
+func (e *Engine) Check(command string, scope Scope) Decision {
+	var d Decision
+	if e == nil {
+		return d
+	}
+
+	for _, p := range e.policies {
+		if !appliesTo(p, scope) {
+			continue
+		}
+
+		if d.Violation == nil {
+			switch {
+			case matchesCommand(p.Deny, command):
+				d.Violation = &Violation{Policy: p.Name, Command: command, Scope: scope, Reason: "command is denied"}
+			case len(p.Allow) > 0 && !matchesCommand(p.Allow, command):
+				d.Violation = &Violation{Policy: p.Name, Command: command, Scope: scope, Reason: "command is not in the allow list"}
+			}
+		}
+
+		if matchesCommand(p.Confirm, command) {
+			d.Confirm = append(d.Confirm, p.Name)
+		}
+
+		if p.MaxWalkSecrets > 0 && ClassOf(command) == ClassBulkRead &&
+			(d.MaxWalkSecrets == 0 || p.MaxWalkSecrets < d.MaxWalkSecrets) {
+			d.MaxWalkSecrets = p.MaxWalkSecrets
+			d.MaxWalkPolicy = p.Name
+		}
+	}
+
+	return d
+}
+
+// appliesTo reports whether policy p applies to scope
+// Instance-wide operations (empty vault) match policies whose vault pattern
+// is empty or "*".
+func appliesTo(p config.Policy, scope Scope) bool {
+	if !matchPattern(p.Provider, scope.Provider) || !matchPattern(p.Instance, scope.Instance) {
+		return false
+	}
+	if scope.Vault == "" {
+		return p.Vault == "" || p.Vault == "*"
+	}
+	return matchPattern(p.Vault, scope.Vault)
+}
+
+// matchPattern matches a glob pattern; an empty pattern or "*" matches
+// anything, including nested Vault mounts such as "team/kv"
+func matchPattern(pattern, value string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// matchesCommand reports whether command is listed by name or class, or the
+// list contains "*"
+func matchesCommand(list []string, command string) bool {
+	class := ClassOf(command)
+	for _, entry := range list {
+		if entry == "*" || entry == command || (class != "" && entry == class) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"errors"
+)
+
+// Error kinds shared by all providers
+// Providers wrap backend errors with NewError so callers can test the kind
+// with errors.Is regardless of which backend produced it.
+var (
+	// ErrNotFound indicates the vault or secret does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrPermissionDenied indicates the caller is authenticated but not authorized
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrAuthExpired indicates missing, invalid or expired credentials
+	ErrAuthExpired = errors.New("authentication failed or expired")
+	// ErrSealed indicates the backend is sealed (HashiCorp Vault)
+	ErrSealed = errors.New("vault is sealed")
+	// ErrUnavailable indicates the backend could not be reached or is overloaded
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// Error is a backend error classified into one of the error kinds
+type Error struct {
+	Kind error // One of the Err* kinds above
+	Err  error // Underlying backend error
+}
+
+// NewError wraps err with a kind
+// A nil kind returns err unchanged so unclassified errors pass through.
+func NewError(kind, err error) error {
+	if kind == nil || err == nil {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Error returns the underlying error message
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap exposes both the kind and the underlying error to errors.Is/As
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// KindOf returns the error kind of err, or nil if it is unclassified
+func KindOf(err error) error {
+	for _, kind := range []error{ErrNotFound, ErrPermissionDenied, ErrAuthExpired, ErrSealed, ErrUnavailable} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// kindNames are stable identifiers for error kinds, used where errors cross
+// a process boundary (e.g. agent responses)
+var kindNames = map[error]string{
+	ErrNotFound:         "not_found",
+	ErrPermissionDenied: "permission_denied",
+	ErrAuthExpired:      "auth_expired",
+	ErrSealed:           "sealed",
+	ErrUnavailable:      "unavailable",
+}
+
+// KindName returns the stable name of the kind of err ("" if unclassified)
+func KindName(err error) string {
+	return kindNames[KindOf(err)]
+}
+
+// KindByName returns the error kind with the given name, or nil
+func KindByName(name string) error {
+	for kind, n := range kindNames {
+		if n == name {
+			return kind
+		}
+	}
+	return nil
+}
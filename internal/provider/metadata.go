@@ -0,0 +1,15 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// MetadataReader is implemented by providers whose listings omit timestamps
+// but that can read a secret's metadata without fetching its value
+// (e.g. the KV v2 metadata endpoint).
+type MetadataReader interface {
+	// GetSecretMetadata returns a secret with its timestamps populated
+	GetSecretMetadata(ctx context.Context, vaultName, secretName string) (*models.Secret, error)
+}
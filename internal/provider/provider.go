@@ -34,11 +34,14 @@ const (
 	FeatureMetadata
 	// FeatureTags indicates the provider supports tagging
 	FeatureTags
+	// FeatureWrite indicates the provider can create and update secrets
+	FeatureWrite
 )
 
 // Config holds provider-specific configuration
 type Config struct {
 	Name     string                 // Provider name
+	Instance string                 // Resolved instance name from config
 	Enabled  bool                   // Whether provider is enabled
 	Default  bool                   // Default provider
 	Settings map[string]interface{} // Provider-specific settings
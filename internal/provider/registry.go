@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -12,17 +13,28 @@ type ProviderFactory func(cfg *Config) (Provider, error)
 type Registry struct {
 	mu        sync.RWMutex
 	factories map[string]ProviderFactory
+	schemas   map[string]*Schema
 }
 
 var defaultRegistry = &Registry{
 	factories: make(map[string]ProviderFactory),
+	schemas:   make(map[string]*Schema),
 }
 
-// Register adds a provider factory to the registry
-func Register(name string, factory ProviderFactory) {
+// Register adds a provider factory and its instance schema to the registry
+// A nil schema passes instance settings through unchecked (plugins).
+func Register(name string, factory ProviderFactory, schema *Schema) {
 	defaultRegistry.mu.Lock()
 	defer defaultRegistry.mu.Unlock()
 	defaultRegistry.factories[name] = factory
+	defaultRegistry.schemas[name] = schema
+}
+
+// GetSchema returns a registered provider's instance schema, or nil
+func GetSchema(name string) *Schema {
+	defaultRegistry.mu.RLock()
+	defer defaultRegistry.mu.RUnlock()
+	return defaultRegistry.schemas[name]
 }
 
 // GetProvider creates a provider instance by name
@@ -38,7 +50,7 @@ func GetProvider(name string, cfg *Config) (Provider, error) {
 	return factory(cfg)
 }
 
-// ListProviders returns all registered provider names
+// ListProviders returns all registered provider names in alphabetical order
 func ListProviders() []string {
 	defaultRegistry.mu.RLock()
 	defer defaultRegistry.mu.RUnlock()
@@ -47,6 +59,7 @@ func ListProviders() []string {
 	for name := range defaultRegistry.factories {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
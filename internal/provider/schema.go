@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// FieldType is the type of an instance setting
+type FieldType int
+
+const (
+	// String is a single string (the zero value)
+	String FieldType = iota
+	// StringList is a list of strings; a single string is read as a list of one
+	StringList
+)
+
+// Field describes one instance setting in config.yaml
+type Field struct {
+	Name     string
+	Type     FieldType
+	Default  interface{} // Used when the setting is absent or empty
+	Required bool        // Checked when the provider is enabled
+	Secret   bool        // A credential: never echoed in errors
+}
+
+// Schema describes the settings accepted by a provider's instances
+// The config loader decodes, defaults and validates every instance against
+// its provider's schema, so providers receive typed settings (string or
+// []string) for every declared field.
+type Schema struct {
+	Fields []Field
+
+	// Enabled is used when config.yaml does not set the provider's enabled flag
+	Enabled bool
+
+	// Validate checks rules spanning several fields; it runs after Decode
+	// and the required-field check, on enabled providers only
+	Validate func(settings map[string]interface{}) error
+}
+
+// Field returns the named field
+func (s *Schema) Field(name string) (Field, bool) {
+	i := slices.IndexFunc(s.Fields, func(f Field) bool { return f.Name == name })
+	if i < 0 {
+		return Field{}, false
+	}
+	return s.Fields[i], true
+}
+
+// Decode converts raw settings from the config file to the declared types
+// Every declared field is present in the result, set to its default or zero
+// value when absent; undeclared settings are an error.
+func (s *Schema) Decode(raw map[string]interface{}) (map[string]interface{}, error) {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := s.Field(name); !ok {
+			return nil, fmt.Errorf("unknown setting '%s'", name)
+		}
+	}
+
+	settings := make(map[string]interface{}, len(s.Fields))
+	for _, f := range s.Fields {
+		v, err := f.decode(raw[f.Name])
+		if err != nil {
+			return nil, err
+		}
+		settings[f.Name] = v
+	}
+	return settings, nil
+}
+
+// Check reports the first missing required field or failed Validate rule
+func (s *Schema) Check(settings map[string]interface{}) error {
+	for _, f := range s.Fields {
+		if isEmpty(settings[f.Name]) && f.Required {
+			return fmt.Errorf("has no %s", f.Name)
+		}
+	}
+	if s.Validate != nil {
+		return s.Validate(settings)
+	}
+	return nil
+}
+
+// decode converts one raw value, applying the default when it is empty
+func (f Field) decode(raw interface{}) (interface{}, error) {
+	if isEmpty(raw) && f.Default != nil {
+		raw = f.Default
+	}
+
+	switch f.Type {
+	case StringList:
+		switch v := raw.(type) {
+		case nil:
+			return []string(nil), nil
+		case string:
+			return []string{v}, nil
+		case []string:
+			return v, nil
+		case []interface{}:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				str, ok := scalar(item)
+				if !ok {
+					return nil, f.typeError("a list of strings", raw)
+				}
+				list = append(list, str)
+			}
+			return list, nil
+		}
+		return nil, f.typeError("a list of strings", raw)
+	default:
+		if raw == nil {
+			return "", nil
+		}
+		str, ok := scalar(raw)
+		if !ok {
+			return nil, f.typeError("a string", raw)
+		}
+		return str, nil
+	}
+}
+
+// typeError reports a value of the wrong type, without the value of secrets
+func (f Field) typeError(want string, raw interface{}) error {
+	if f.Secret {
+		return fmt.Errorf("setting '%s' must be %s", f.Name, want)
+	}
+	return fmt.Errorf("setting '%s' must be %s, got %v", f.Name, want, raw)
+}
+
+// scalar formats strings, numbers and booleans as a string
+// YAML reads unquoted values such as 8200 or true as non-strings.
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// isEmpty reports whether a setting is absent, an empty string or an empty list
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"iter"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Streamer is implemented by providers that can yield listings incrementally
+// (e.g. as SDK pagers return pages) instead of buffering the whole result.
+// Streamed items come in backend order; the List* methods sort by name.
+type Streamer interface {
+	// StreamVaults yields vaults as they are fetched
+	StreamVaults(ctx context.Context) iter.Seq2[*models.Vault, error]
+
+	// StreamSecrets yields the secrets of a vault as they are fetched
+	StreamSecrets(ctx context.Context, vaultName string) iter.Seq2[*models.Secret, error]
+}
+
+// StreamVaults yields the vaults of p, streaming when p implements Streamer
+// and falling back to ListVaults otherwise
+func StreamVaults(ctx context.Context, p Provider) iter.Seq2[*models.Vault, error] {
+	if s, ok := p.(Streamer); ok {
+		return s.StreamVaults(ctx)
+	}
+
+	return func(yield func(*models.Vault, error) bool) {
+		vaults, err := p.ListVaults(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, v := range vaults {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// StreamSecrets yields the secrets of a vault, streaming when p implements
+// Streamer and falling back to ListSecrets otherwise
+func StreamSecrets(ctx context.Context, p Provider, vaultName string) iter.Seq2[*models.Secret, error] {
+	if s, ok := p.(Streamer); ok {
+		return s.StreamSecrets(ctx, vaultName)
+	}
+
+	return func(yield func(*models.Secret, error) bool) {
+		secrets, err := p.ListSecrets(ctx, vaultName)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, s := range secrets {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Versioner is implemented by providers that can read a specific version of
+// a secret (FeatureVersioning). GetSecret always returns the current version.
+type Versioner interface {
+	// GetSecretVersion retrieves a specific version of a secret
+	GetSecretVersion(ctx context.Context, vaultName, secretName, version string) (*models.SecretValue, error)
+}
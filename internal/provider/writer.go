@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// Writer is implemented by providers that can create and update secrets
+// (FeatureWrite)
+type Writer interface {
+	// SetSecret creates a secret or replaces its value. Value, Fields,
+	// ContentType and Tags of secret are stored; other fields are ignored.
+	// It returns the stored secret without its value.
+	SetSecret(ctx context.Context, vaultName, secretName string, secret *models.SecretValue) (*models.Secret, error)
+}
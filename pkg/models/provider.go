@@ -2,8 +2,8 @@ package models
 
 // ProviderInfo holds metadata about a provider
 type ProviderInfo struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Available   bool     `json:"available"`
-	Features    []string `json:"features,omitempty"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Available   bool     `json:"available" yaml:"available"`
+	Features    []string `json:"features,omitempty" yaml:"features,omitempty"`
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SecretRef identifies a secret by location only (never carries a value)
+type SecretRef struct {
+	Provider string `json:"provider" yaml:"provider"`
+	Instance string `json:"instance" yaml:"instance"`
+	Vault    string `json:"vault" yaml:"vault"`
+	Secret   string `json:"secret" yaml:"secret"`
+}
+
+// String returns the reference as provider/instance/vault/secret
+// Each segment is path-escaped, so slashes inside nested Vault mounts or
+// secret paths are encoded as %2F and the reference always has four segments.
+func (r SecretRef) String() string {
+	segments := []string{r.Provider, r.Instance, r.Vault, r.Secret}
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// ParseSecretRef parses a reference produced by SecretRef.String
+func ParseSecretRef(s string) (SecretRef, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 4 {
+		return SecretRef{}, fmt.Errorf("invalid secret reference '%s' (expected provider/instance/vault/secret)", s)
+	}
+
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return SecretRef{}, fmt.Errorf("invalid secret reference '%s': %w", s, err)
+		}
+		parts[i] = unescaped
+	}
+
+	ref := SecretRef{
+		Provider: parts[0],
+		Instance: parts[1],
+		Vault:    parts[2],
+		Secret:   parts[3],
+	}
+
+	if ref.Provider == "" || ref.Vault == "" || ref.Secret == "" {
+		return SecretRef{}, fmt.Errorf("invalid secret reference '%s' (expected provider/instance/vault/secret)", s)
+	}
+
+	return ref, nil
+}
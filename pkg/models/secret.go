@@ -1,17 +1,31 @@
 package models
 
+import "time"
+
 // Secret represents a secret (without value)
 type Secret struct {
-	Name      string `json:"name"`
-	VaultName string `json:"vault"`
-	Provider  string `json:"provider"`
-	Enabled   bool   `json:"enabled,omitempty"`
+	Name      string     `json:"name" yaml:"name"`
+	VaultName string     `json:"vault" yaml:"vault"`
+	Provider  string     `json:"provider" yaml:"provider"`
+	Instance  string     `json:"instance,omitempty" yaml:"instance,omitempty"` // Set when listing across instances
+	Enabled   bool       `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	CreatedOn *time.Time `json:"createdOn,omitempty" yaml:"createdOn,omitempty"` // Timestamps are set when the provider lists them cheaply
+	UpdatedOn *time.Time `json:"updatedOn,omitempty" yaml:"updatedOn,omitempty"`
+	ExpiresOn *time.Time `json:"expiresOn,omitempty" yaml:"expiresOn,omitempty"`
 }
 
 // SecretValue includes the actual secret value
 type SecretValue struct {
-	Name      string `json:"name"`
-	Value     string `json:"value"`
-	VaultName string `json:"vault"`
-	Provider  string `json:"provider"`
+	Name        string            `json:"name" yaml:"name"`
+	Value       string            `json:"value" yaml:"value"`
+	VaultName   string            `json:"vault" yaml:"vault"`
+	Provider    string            `json:"provider" yaml:"provider"`
+	Fields      map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"` // All key/value pairs for multi-field secrets
+	Version     string            `json:"version,omitempty" yaml:"version,omitempty"`
+	ContentType string            `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	CreatedOn   *time.Time        `json:"createdOn,omitempty" yaml:"createdOn,omitempty"`
+	UpdatedOn   *time.Time        `json:"updatedOn,omitempty" yaml:"updatedOn,omitempty"`
+	ExpiresOn   *time.Time        `json:"expiresOn,omitempty" yaml:"expiresOn,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"` // Provider-specific metadata
 }
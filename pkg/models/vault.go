@@ -2,7 +2,8 @@ package models
 
 // Vault represents a secrets vault/backend
 type Vault struct {
-	Name     string            `json:"name"`
-	Provider string            `json:"provider"`
-	Metadata map[string]string `json:"metadata,omitempty"`
+	Name     string            `json:"name" yaml:"name"`
+	Provider string            `json:"provider" yaml:"provider"`
+	Instance string            `json:"instance,omitempty" yaml:"instance,omitempty"` // Set when listing across instances
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 }
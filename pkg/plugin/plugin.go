@@ -0,0 +1,58 @@
+// Package plugin lets a separate binary serve a smart-keyvault provider
+//
+// A plugin is an executable named smart-keyvault-provider-<name> placed in
+// the plugin directory or on PATH. Its main function passes a factory to
+// Serve:
+//
+//	func main() {
+//		if err := plugin.Serve(NewProvider); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The factory receives the instance's settings from config.yaml and returns a
+// Provider, optionally also implementing Versioner, MetadataReader or Writer.
+// Wrap backend errors with NewError so the CLI exits with the matching code.
+package plugin
+
+import "github.com/ylchen07/smart-keyvault/internal/provider"
+
+// The provider contract, shared with the built-in providers
+type (
+	// Provider is the interface every plugin implements
+	Provider = provider.Provider
+	// Versioner reads specific versions of a secret (FeatureVersioning)
+	Versioner = provider.Versioner
+	// MetadataReader reads a secret's timestamps without its value
+	MetadataReader = provider.MetadataReader
+	// Writer creates and updates secrets (FeatureWrite)
+	Writer = provider.Writer
+	// Feature is an optional provider capability
+	Feature = provider.Feature
+	// Config carries the provider name, instance name and settings
+	Config = provider.Config
+	// Factory creates the provider served by a plugin
+	Factory = provider.ProviderFactory
+)
+
+// Optional provider capabilities
+const (
+	FeatureVersioning = provider.FeatureVersioning
+	FeatureMetadata   = provider.FeatureMetadata
+	FeatureTags       = provider.FeatureTags
+	FeatureWrite      = provider.FeatureWrite
+)
+
+// Error kinds, mapped by the CLI to its exit codes
+var (
+	ErrNotFound         = provider.ErrNotFound
+	ErrPermissionDenied = provider.ErrPermissionDenied
+	ErrAuthExpired      = provider.ErrAuthExpired
+	ErrSealed           = provider.ErrSealed
+	ErrUnavailable      = provider.ErrUnavailable
+)
+
+// NewError wraps err with one of the error kinds
+func NewError(kind, err error) error {
+	return provider.NewError(kind, err)
+}
@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"errors"
+
+	"github.com/ylchen07/smart-keyvault/internal/provider"
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// ProtocolVersion is the plugin protocol spoken by this package
+// The handshake fails when the host and the plugin disagree.
+const ProtocolVersion = 1
+
+// BinaryPrefix is the file name prefix of plugin binaries: a provider named
+// "example" is served by smart-keyvault-provider-example
+const BinaryPrefix = "smart-keyvault-provider-"
+
+// Operations sent by the host, one JSON object per line on the plugin's stdin
+const (
+	OpHandshake      = "handshake"
+	OpListVaults     = "list-vaults"
+	OpListSecrets    = "list-secrets"
+	OpGetSecret      = "get-secret"
+	OpGetVersion     = "get-secret-version"
+	OpSecretMetadata = "secret-metadata"
+	OpSetSecret      = "set-secret"
+)
+
+// Request is one line sent to a plugin
+// The first request is always a handshake carrying the instance's settings.
+type Request struct {
+	Op       string                 `json:"op"`
+	Protocol int                    `json:"protocol,omitempty"` // Handshake only
+	Provider string                 `json:"provider,omitempty"` // Handshake only
+	Instance string                 `json:"instance,omitempty"` // Handshake only
+	Settings map[string]interface{} `json:"settings,omitempty"` // Handshake only
+	Vault    string                 `json:"vault,omitempty"`
+	Secret   string                 `json:"secret,omitempty"`
+	Version  string                 `json:"version,omitempty"`
+	Value    *models.SecretValue    `json:"value,omitempty"` // New value for set-secret
+}
+
+// Response is one line written by a plugin to its stdout
+// Error kinds are sent by name so the CLI keeps its exit codes.
+type Response struct {
+	Error     string              `json:"error,omitempty"`
+	ErrorKind string              `json:"errorKind,omitempty"`
+	Protocol  int                 `json:"protocol,omitempty"` // Handshake only
+	Features  []string            `json:"features,omitempty"` // Handshake only
+	Vaults    []*models.Vault     `json:"vaults,omitempty"`
+	Secrets   []*models.Secret    `json:"secrets,omitempty"`
+	Secret    *models.Secret      `json:"secret,omitempty"`
+	Value     *models.SecretValue `json:"value,omitempty"`
+}
+
+// errorResponse encodes err for the wire
+func errorResponse(err error) *Response {
+	return &Response{Error: err.Error(), ErrorKind: provider.KindName(err)}
+}
+
+// Err decodes the response error, restoring its kind
+func (r *Response) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return provider.NewError(provider.KindByName(r.ErrorKind), errors.New(r.Error))
+}
+
+// featureNames are the wire names of provider features
+var featureNames = map[Feature]string{
+	FeatureVersioning: "versioning",
+	FeatureMetadata:   "metadata",
+	FeatureTags:       "tags",
+	FeatureWrite:      "write",
+}
+
+// FeatureNames returns the wire names of the features p supports
+func FeatureNames(p Provider) []string {
+	var names []string
+	for _, feature := range []Feature{FeatureVersioning, FeatureMetadata, FeatureTags, FeatureWrite} {
+		if p.SupportsFeature(feature) {
+			names = append(names, featureNames[feature])
+		}
+	}
+	return names
+}
+
+// FeatureByName returns the feature with the given wire name
+func FeatureByName(name string) (Feature, bool) {
+	for feature, n := range featureNames {
+		if n == name {
+			return feature, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ylchen07/smart-keyvault/pkg/models"
+)
+
+// maxRequestSize bounds one request line (set-secret carries the value)
+const maxRequestSize = 16 << 20
+
+// Serve answers requests on stdin and stdout until stdin is closed
+// Anything the plugin wants to log must go to stderr.
+func Serve(factory Factory) error {
+	return serve(factory, os.Stdin, os.Stdout)
+}
+
+// serve runs the protocol over r and w
+func serve(factory Factory, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxRequestSize)
+	encoder := json.NewEncoder(w)
+
+	var p Provider
+	for scanner.Scan() {
+		var req Request
+		var resp *Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = errorResponse(fmt.Errorf("invalid request: %w", err))
+		} else if p == nil {
+			p, resp = handshake(factory, &req)
+		} else {
+			resp = dispatch(context.Background(), p, &req)
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// handshake creates the provider from the first request
+func handshake(factory Factory, req *Request) (Provider, *Response) {
+	if req.Op != OpHandshake {
+		return nil, errorResponse(fmt.Errorf("expected %s, got %s", OpHandshake, req.Op))
+	}
+	if req.Protocol != ProtocolVersion {
+		return nil, errorResponse(fmt.Errorf("unsupported plugin protocol %d (plugin speaks %d)", req.Protocol, ProtocolVersion))
+	}
+
+	settings := req.Settings
+	if settings == nil {
+		settings = make(map[string]interface{})
+	}
+	p, err := factory(&Config{Name: req.Provider, Instance: req.Instance, Enabled: true, Settings: settings})
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+	return p, &Response{Protocol: ProtocolVersion, Features: FeatureNames(p)}
+}
+
+// dispatch runs one request against the provider
+func dispatch(ctx context.Context, p Provider, req *Request) *Response {
+	switch req.Op {
+	case OpListVaults:
+		vaults, err := p.ListVaults(ctx)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Vaults: vaults}
+
+	case OpListSecrets:
+		secrets, err := p.ListSecrets(ctx, req.Vault)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secrets: secrets}
+
+	case OpGetSecret:
+		value, err := p.GetSecret(ctx, req.Vault, req.Secret)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Value: value}
+
+	case OpGetVersion:
+		versioner, ok := p.(Versioner)
+		if !ok {
+			return errorResponse(fmt.Errorf("provider %s does not support reading secret versions", p.Name()))
+		}
+		value, err := versioner.GetSecretVersion(ctx, req.Vault, req.Secret, req.Version)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Value: value}
+
+	case OpSecretMetadata:
+		reader, ok := p.(MetadataReader)
+		if !ok {
+			return &Response{Secret: &models.Secret{Name: req.Secret, VaultName: req.Vault, Provider: p.Name(), Enabled: true}}
+		}
+		secret, err := reader.GetSecretMetadata(ctx, req.Vault, req.Secret)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secret: secret}
+
+	case OpSetSecret:
+		writer, ok := p.(Writer)
+		if !ok {
+			return errorResponse(fmt.Errorf("provider %s does not support writing secrets", p.Name()))
+		}
+		if req.Value == nil {
+			return errorResponse(fmt.Errorf("set-secret requires a value"))
+		}
+		secret, err := writer.SetSecret(ctx, req.Vault, req.Secret, req.Value)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secret: secret}
+
+	default:
+		return errorResponse(fmt.Errorf("unknown operation: %s", req.Op))
+	}
+}
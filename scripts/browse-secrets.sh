@@ -1,6 +1,10 @@
 #!/usr/bin/env bash
 set -e
 
+CURRENT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+# shellcheck source=scripts/utils.sh
+source "$CURRENT_DIR/utils.sh"
+
 # Get binary path from tmux environment
 BINARY="${SMART_KEYVAULT_BIN:-smart-keyvault}"
 FZF_HEIGHT="${SMART_KEYVAULT_FZF_HEIGHT:-60%}"
@@ -18,13 +22,58 @@ if ! command -v fzf &> /dev/null; then
     exit 1
 fi
 
-# Step 1: Select provider
-provider=$("$BINARY" list-providers | fzf-tmux -p "$FZF_WIDTH,$FZF_HEIGHT" --prompt="Select Provider: " --border=rounded || true)
+# Copy a secret to the clipboard and report the outcome in tmux
+# Usage: copy_secret <label> <get-secret arguments...>
+copy_secret() {
+    local label="$1"
+    shift
+
+    local status=0
+    "$BINARY" get-secret "$@" --copy >/dev/null 2>&1 || status=$?
+
+    if [[ $status -eq 0 ]]; then
+        tmux display-message "✓ Secret '$label' copied to clipboard!"
+    else
+        tmux display-message "✗ Failed to retrieve secret '$label': $(exit_code_message "$status")"
+    fi
+    return $status
+}
+
+# Quick mode: pick from favorites and recently used secrets
+if [[ "$1" == "--quick" ]]; then
+    recent=$("$BINARY" recent 2>/dev/null || true)
+
+    if [[ -n "$recent" ]]; then
+        ref=$(echo "$recent" | fzf-tmux -p "$FZF_WIDTH,$FZF_HEIGHT" --prompt="Recent Secret: " --border=rounded || true)
+
+        if [[ -z "$ref" ]]; then
+            exit 0  # User cancelled
+        fi
+
+        secret="${ref##*/}"
+        copy_secret "$secret" --ref "$ref"
+        exit $?
+    fi
+
+    # Nothing used yet - fall through to the full workflow
+    tmux display-message "No recent secrets yet, starting full browse"
+fi
+
+# Step 1: Select provider (aliases are listed first as @name)
+aliases=$("$BINARY" alias list 2>/dev/null | cut -f1 | sed 's/^/@/' || true)
+provider=$({ [[ -n "$aliases" ]] && echo "$aliases"; "$BINARY" list-providers; } | fzf-tmux -p "$FZF_WIDTH,$FZF_HEIGHT" --prompt="Select Alias or Provider: " --border=rounded || true)
 
 if [[ -z "$provider" ]]; then
     exit 0  # User cancelled
 fi
 
+# An alias resolves straight to a secret
+if [[ "$provider" == @* ]]; then
+    alias="${provider#@}"
+    copy_secret "$alias" "$alias"
+    exit $?
+fi
+
 # Step 2: Select vault
 vault=$("$BINARY" list-vaults --provider "$provider" | fzf-tmux -p "$FZF_WIDTH,$FZF_HEIGHT" --prompt="Select Vault ($provider): " --border=rounded || true)
 
@@ -40,9 +89,4 @@ if [[ -z "$secret" ]]; then
 fi
 
 # Step 4: Get secret and copy to clipboard
-if "$BINARY" get-secret --provider "$provider" --vault "$vault" --name "$secret" --copy 2>&1; then
-    tmux display-message "✓ Secret '$secret' copied to clipboard!"
-else
-    tmux display-message "✗ Failed to retrieve secret '$secret'"
-    exit 1
-fi
+copy_secret "$secret" --provider "$provider" --vault "$vault" --name "$secret"
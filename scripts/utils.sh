@@ -33,3 +33,16 @@ validate_prerequisites() {
 
     return 0
 }
+
+# Describe a smart-keyvault exit code (see "Exit Codes" in README.md)
+exit_code_message() {
+    case "$1" in
+        2) echo "invalid usage" ;;
+        3) echo "not found" ;;
+        4) echo "permission denied" ;;
+        5) echo "authentication expired - log in again" ;;
+        6) echo "vault is sealed" ;;
+        7) echo "provider unreachable" ;;
+        *) echo "unexpected error" ;;
+    esac
+}